@@ -14,22 +14,29 @@ limitations under the License.
 package citf
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
 	citfoptions "github.com/openebs/CITF/citf_options"
-	"github.com/openebs/CITF/common"
 	"github.com/openebs/CITF/config"
 	"github.com/openebs/CITF/environments"
 	"github.com/openebs/CITF/environments/docker"
-	"github.com/openebs/CITF/environments/minikube"
+	// below packages register themselves as environments
+	_ "github.com/openebs/CITF/environments/existing"
+	_ "github.com/openebs/CITF/environments/kind"
+	_ "github.com/openebs/CITF/environments/minikube"
 	"github.com/openebs/CITF/utils/k8s"
 	"github.com/openebs/CITF/utils/log"
 )
 
-var logger log.Logger
-
 // CITF is a struct which will be the driver for all functionalities of this framework
 type CITF struct {
+	// Config is the resolved configuration of this instance, every other field reads from it.
+	// So different instances can target different clusters.
+	Config       *config.Config
 	Environment  environments.Environment
 	K8S          k8s.K8S
 	Docker       docker.Docker
@@ -37,14 +44,19 @@ type CITF struct {
 	Logger       log.Logger
 }
 
-// getEnvironment returns the environment according to the config
-func getEnvironment() (environments.Environment, error) {
-	switch config.Environment() {
-	case common.Minikube:
-		return minikube.NewMinikube(), nil
-	default:
-		return nil, fmt.Errorf("platform: %q is not suppported by CITF", config.Environment())
+// conf returns the configuration of this instance, package level configuration if it is not set
+func (citfInstance *CITF) conf() *config.Config {
+	if citfInstance.Config != nil {
+		return citfInstance.Config
 	}
+	return config.Global()
+}
+
+// getEnvironment returns the environment according to the supplied config.
+// Environment is looked up in the registry of package environments,
+// so any environment registered there (even out of this tree) can be used.
+func getEnvironment(conf *config.Config) (environments.Environment, error) {
+	return environments.Get(conf.Environment(), conf)
 }
 
 // Reload reloads all the fields of citfInstance according to supplied `citfCreateOptions`
@@ -52,18 +64,30 @@ func (citfInstance *CITF) Reload(citfCreateOptions *citfoptions.CreateOptions) e
 	// Here, we don't want to return fatal error since we want to continue
 	// executing the function with default configuration even if it fails
 	// so we simply log any error and continue
-	logger.LogError(config.LoadConf(citfCreateOptions.ConfigPath), "error loading config file")
+	conf, err := config.NewConfigForProfile(citfCreateOptions.ConfigPath, citfCreateOptions.Profile)
+	conf.Logger().LogError(err, "error loading config file")
+	citfInstance.Config = conf
+
+	// package level configurations are still loaded for the callers which use them directly
+	conf.Logger().LogError(config.LoadConfProfile(citfCreateOptions.ConfigPath, citfCreateOptions.Profile), "error loading config file in package level configurations")
 
 	if citfCreateOptions.EnvironmentInclude {
-		environ, err := getEnvironment()
+		environ, err := getEnvironment(conf)
 		if err != nil {
 			return err
 		}
 		citfInstance.Environment = environ
+
+		// kube-config written by the environment in this run (if any) is used by K8S
+		if provider, ok := environ.(environments.KubeConfigProvider); ok {
+			if _, err := os.Stat(provider.KubeConfigPath()); err == nil {
+				conf.SetEnvironmentKubeConfigPath(provider.KubeConfigPath())
+			}
+		}
 	}
 
 	if citfCreateOptions.K8SInclude {
-		k8sInstance, err := k8s.NewK8S()
+		k8sInstance, err := k8s.NewK8SForConfig(conf)
 		if err != nil {
 			return err
 		}
@@ -71,17 +95,106 @@ func (citfInstance *CITF) Reload(citfCreateOptions *citfoptions.CreateOptions) e
 	}
 
 	if citfCreateOptions.DockerInclude {
-		citfInstance.Docker = docker.NewDocker()
+		citfInstance.Docker = docker.NewDockerForConfig(conf)
 	}
 
 	if citfCreateOptions.LoggerInclude {
-		citfInstance.Logger = log.Logger{}
+		citfInstance.Logger = conf.Logger()
+	}
+
+	citfInstance.DebugEnabled = conf.Debug()
+	if conf.Logger().Enabled(log.LevelDebug) {
+		// So that logs show which configuration was in effect
+		dump := &bytes.Buffer{}
+		conf.Logger().LogError(conf.Dump(dump), "error in configuration")
+		conf.Logger().Debug("configuration in effect", "configuration", dump.String())
+	}
+	return nil
+}
+
+// clusterReadyCheckInterval is the time to wait between two readiness checks of the cluster
+const clusterReadyCheckInterval = 2 * time.Second
+
+// SetupEnvironment sets up the Environment, waits until its api server answers and all the nodes are Ready
+// and then creates K8S for it. So no one needs to reload CITF or sleep after the setup of the environment.
+// It waits for the cluster at most `clusterReadyTimeout` of the configuration.
+func (citfInstance *CITF) SetupEnvironment() error {
+	return citfInstance.SetupEnvironmentWithTimeout(citfInstance.conf().ClusterReadyTimeout())
+}
+
+// SetupEnvironmentWithTimeout is same as SetupEnvironment except that it waits for the cluster at most `timeout`
+func (citfInstance *CITF) SetupEnvironmentWithTimeout(timeout time.Duration) error {
+	if citfInstance.Environment == nil {
+		return fmt.Errorf("environment is not included in this CITF instance")
+	}
+
+	err := citfInstance.Environment.Setup()
+	if err != nil {
+		return fmt.Errorf("error setting up environment %q. Error: %+v", citfInstance.Environment.Name(), err)
+	}
+
+	k8sInstance, err := k8s.NewK8SForConfig(citfInstance.conf())
+	if err != nil {
+		return fmt.Errorf("error creating K8S for environment %q. Error: %+v", citfInstance.Environment.Name(), err)
+	}
+
+	err = k8sInstance.WaitUntilClusterIsReadyOrTimeout(timeout, clusterReadyCheckInterval)
+	if err != nil {
+		return fmt.Errorf("environment %q is set up but its cluster is not usable. Error: %+v", citfInstance.Environment.Name(), err)
+	}
+
+	citfInstance.K8S = k8sInstance
+	return nil
+}
+
+// TeardownEnvironment tears the Environment down as per `teardownPolicy` of the configuration,
+// `succeeded` tells whether the tests passed. When the environment is kept, the kube-config to reach it
+// and the namespaces created by this run are logged, so that the failure can be inspected.
+func (citfInstance *CITF) TeardownEnvironment(succeeded bool) error {
+	if citfInstance.Environment == nil {
+		return fmt.Errorf("environment is not included in this CITF instance")
+	}
+
+	var preservedNamespaces []string
+	if !citfInstance.conf().ShouldTeardown(succeeded) && citfInstance.K8S.Clientset != nil {
+		var err error
+		preservedNamespaces, err = citfInstance.K8S.RunNamespaces()
+		citfInstance.conf().Logger().LogError(err, "error listing namespaces created by this run")
 	}
 
-	citfInstance.DebugEnabled = config.Debug()
+	_, err := environments.TeardownWithPolicy(citfInstance.Environment, citfInstance.conf(), succeeded, preservedNamespaces)
+	if err != nil {
+		return fmt.Errorf("error tearing down environment %q. Error: %+v", citfInstance.Environment.Name(), err)
+	}
 	return nil
 }
 
+// WithOutput returns a copy of this instance which writes all its logs, including those of its K8S, Docker
+// and Environment, only to w instead of the configured sinks, e.g. to GinkgoWriter so that the output is
+// shown only if the spec fails or with -v. Configurations and clients are shared with this instance.
+func (citfInstance *CITF) WithOutput(w io.Writer) CITF {
+	conf := citfInstance.conf().WithLogSinks(w)
+
+	scoped := *citfInstance
+	scoped.Config = conf
+	scoped.Logger = conf.Logger()
+	scoped.K8S = citfInstance.K8S.WithLogger(conf.Logger())
+	if citfInstance.Docker.Config != nil || citfInstance.Docker.Client != nil {
+		scoped.Docker = citfInstance.Docker.WithConfig(conf).(docker.Docker)
+	}
+	if configurable, ok := citfInstance.Environment.(environments.Configurable); ok {
+		scoped.Environment = configurable.WithConfig(conf)
+	}
+	return scoped
+}
+
+// ForTest returns a copy of this instance which writes all its logs to the output of the test t,
+// so parallel tests don't interleave their output and it is shown only if the test fails or with -v.
+// It must be used only within t, as the output of a test can not be written after it has completed.
+func (citfInstance *CITF) ForTest(t log.TestingT) CITF {
+	return citfInstance.WithOutput(log.TestWriter(t))
+}
+
 // NewCITF returns CITF struct filled according to supplied `citfCreateOptions`.
 // One need this in order to use any functionality of this framework.
 func NewCITF(citfCreateOptions *citfoptions.CreateOptions) (citfInstance CITF, err error) {
@@ -2,7 +2,9 @@ package citfoptions
 
 // CreateOptions specifies which fields of CITF should be included when created or reloadedd
 type CreateOptions struct {
-	ConfigPath         string
+	ConfigPath string
+	// Profile is the profile of the config file which should be used, `CITF_PROFILE` takes precedence over it
+	Profile            string
 	EnvironmentInclude bool
 	K8SInclude         bool
 	DockerInclude      bool
@@ -18,6 +18,19 @@ const (
 	Minikube = "minikube"
 	// Docker is the name of Docker which is "docker"
 	Docker = "docker"
+	// Kind is the name of Kind (Kubernetes IN Docker) which is "kind"
+	Kind = "kind"
+	// Existing is the name of the environment which uses an already running cluster, which is "existing"
+	Existing = "existing"
 	// Kubectl is the name of Kubectl which is "kubectl"
 	Kubectl = "kubectl"
 )
+
+const (
+	// LabelPrefix is the prefix of the keys of the labels which CITF puts on the objects it creates
+	LabelPrefix = "citf.openebs.io/"
+	// RunIDLabel is the key of the label which holds the ID of the CITF run that created the object
+	RunIDLabel = "citf.openebs.io/run-id"
+	// CreatedAtLabel is the key of the label which holds the time (unix seconds) when CITF created the object
+	CreatedAtLabel = "citf.openebs.io/created-at"
+)
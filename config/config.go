@@ -14,33 +14,199 @@ limitations under the License.
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 
 	"os"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/openebs/CITF/common"
 	"github.com/openebs/CITF/utils/log"
-	yaml "gopkg.in/yaml.v2"
 )
 
-// Configuration is struct to hold the configurations of CITF
+var logger log.Logger
+
+// Configuration is struct to hold the configurations of CITF.
+// Configurations specific to an environment are in the section of that environment.
 type Configuration struct {
-	Environment    string `json:"environment,omitempty" yaml:"environment,omitempty"`
-	Debug          bool   `json:"debug,omitempty" yaml:"debug,omitempty"`
-	KubeMasterURL  string `json:"kubeMasterURL,omitempty" yaml:"kubeMasterURL,omitempty"`
-	KubeConfigPath string `json:"kubeConfigPath,omitempty" yaml:"kubeConfigPath,omitempty"`
+	Environment         string        `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Debug               bool          `json:"debug,omitempty" yaml:"debug,omitempty"`
+	KubeMasterURL       string        `json:"kubeMasterURL,omitempty" yaml:"kubeMasterURL,omitempty"`
+	KubeConfigPath      string        `json:"kubeConfigPath,omitempty" yaml:"kubeConfigPath,omitempty"`
+	RunID               string        `json:"runID,omitempty" yaml:"runID,omitempty"`
+	ClusterReadyTimeout time.Duration `json:"clusterReadyTimeout,omitempty" yaml:"clusterReadyTimeout,omitempty"`
+	TeardownPolicy      string        `json:"teardownPolicy,omitempty" yaml:"teardownPolicy,omitempty"`
+
+	Log      LogConfiguration      `json:"log,omitempty" yaml:"log,omitempty"`
+	Kube     KubeConfiguration     `json:"kube,omitempty" yaml:"kube,omitempty"`
+	Kind     KindConfiguration     `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Docker   DockerConfiguration   `json:"docker,omitempty" yaml:"docker,omitempty"`
+	Minikube MinikubeConfiguration `json:"minikube,omitempty" yaml:"minikube,omitempty"`
+	Existing ExistingConfiguration `json:"existing,omitempty" yaml:"existing,omitempty"`
+}
+
+// Teardown policies i.e. values of `teardownPolicy`, which tell when the environment is torn down
+const (
+	// TeardownAlways tears the environment down whether the tests passed or not
+	TeardownAlways = "always"
+	// TeardownNever never tears the environment down, so that it can be inspected or reused
+	TeardownNever = "never"
+	// TeardownOnSuccess tears the environment down only if the tests passed, so that failures can be inspected
+	TeardownOnSuccess = "on-success"
+)
+
+// LogConfiguration is the section of configurations of the logger of CITF
+type LogConfiguration struct {
+	// Level is the minimum level of the records which are written i.e. debug, info, warn or error.
+	// `debug: true` lowers it to debug.
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+	// Format is the format of the records i.e. text or json
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Sinks are where records are written, each is either stdout, stderr or path of a file which is appended to
+	Sinks []string `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+}
+
+// KubeConfiguration is the section of configurations of the client which talks to kubernetes.
+// Zero values mean client-go's defaults.
+type KubeConfiguration struct {
+	// Context is the context of kube-config to use instead of its current context
+	Context string `json:"context,omitempty" yaml:"context,omitempty"`
+	// QPS is the maximum queries per second to the api server, Burst is the maximum burst for throttle
+	QPS   float32 `json:"qps,omitempty" yaml:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty" yaml:"burst,omitempty"`
+	// Timeout is the timeout of every request to the api server
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// ImpersonateUser and ImpersonateGroups are the user and groups the client acts as
+	ImpersonateUser   string   `json:"impersonateUser,omitempty" yaml:"impersonateUser,omitempty"`
+	ImpersonateGroups []string `json:"impersonateGroups,omitempty" yaml:"impersonateGroups,omitempty"`
+}
+
+// KindConfiguration is the section of configurations of kind environment
+type KindConfiguration struct {
+	ClusterName string        `json:"clusterName,omitempty" yaml:"clusterName,omitempty"`
+	Nodes       int           `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	NodeImage   string        `json:"nodeImage,omitempty" yaml:"nodeImage,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// DockerConfiguration is the section of configurations of docker environment
+type DockerConfiguration struct {
+	SocketPath string `json:"socketPath,omitempty" yaml:"socketPath,omitempty"`
+}
+
+// MinikubeConfiguration is the section of configurations of minikube environment
+type MinikubeConfiguration struct {
+	Driver            string        `json:"driver,omitempty" yaml:"driver,omitempty"`
+	CPUs              int           `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	Memory            string        `json:"memory,omitempty" yaml:"memory,omitempty"`
+	KubernetesVersion string        `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+	FeatureGates      []string      `json:"featureGates,omitempty" yaml:"featureGates,omitempty"`
+	ExtraConfig       []string      `json:"extraConfig,omitempty" yaml:"extraConfig,omitempty"`
+	Profile           string        `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Timeout           time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// ExistingConfiguration is the section of configurations of existing environment
+type ExistingConfiguration struct {
+	RequiredNamespaces []string `json:"requiredNamespaces,omitempty" yaml:"requiredNamespaces,omitempty"`
+	RequiredCRDs       []string `json:"requiredCRDs,omitempty" yaml:"requiredCRDs,omitempty"`
+}
+
+// isTeardownPolicy tells if the supplied value is a teardown policy, blank means not set
+func isTeardownPolicy(policy string) bool {
+	switch policy {
+	case "", TeardownAlways, TeardownNever, TeardownOnSuccess:
+		return true
+	}
+	return false
+}
+
+// validate checks the values which can be parsed but are not acceptable
+func (configuration Configuration) validate() error {
+	var problems []string
+	if configuration.Log.Level != "" {
+		if _, err := log.ParseLevel(configuration.Log.Level); err != nil {
+			problems = append(problems, "log.level: "+err.Error())
+		}
+	}
+	switch log.Format(configuration.Log.Format) {
+	case "", log.FormatText, log.FormatJSON:
+	default:
+		problems = append(problems, fmt.Sprintf("log.format must be %q or %q", log.FormatText, log.FormatJSON))
+	}
+	if configuration.Kube.QPS < 0 {
+		problems = append(problems, "kube.qps must not be negative")
+	}
+	if configuration.Kube.Burst < 0 {
+		problems = append(problems, "kube.burst must not be negative")
+	}
+	if len(configuration.Kube.ImpersonateGroups) != 0 && configuration.Kube.ImpersonateUser == "" {
+		problems = append(problems, "kube.impersonateGroups requires kube.impersonateUser")
+	}
+	if !isTeardownPolicy(configuration.TeardownPolicy) {
+		problems = append(problems, fmt.Sprintf("teardownPolicy must be one of %q, %q or %q", TeardownAlways, TeardownNever, TeardownOnSuccess))
+	}
+	if configuration.Kind.Nodes < 0 {
+		problems = append(problems, "kind.nodes must not be negative")
+	}
+	if configuration.Minikube.CPUs < 0 {
+		problems = append(problems, "minikube.cpus must not be negative")
+	}
+	for key, duration := range map[string]time.Duration{
+		"clusterReadyTimeout": configuration.ClusterReadyTimeout,
+		"kube.timeout":        configuration.Kube.Timeout,
+		"kind.timeout":        configuration.Kind.Timeout,
+		"minikube.timeout":    configuration.Minikube.Timeout,
+	} {
+		if duration < 0 {
+			problems = append(problems, key+" must not be negative")
+		}
+	}
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
 }
 
 var (
 	// Conf will contain configurations for CITF
 	Conf        Configuration
 	defaultConf Configuration
+
+	// environmentKubeConfigPath is the path of kube-config written by the environment in use, if any
+	environmentKubeConfigPath string
+
+	// globalConfig is the Config which package level functions use
+	globalConfig = &Config{conf: &Conf, zeroKeys: map[string]bool{}, environmentKubeConfigPath: &environmentKubeConfigPath}
 )
 
+// Config is the resolved configuration of a CITF instance. Configurations are looked up
+// in environment variables, then in its own Configuration (loaded from the config file) and
+// then in the default configuration. Environment variables apply to every Config of the process.
+// Different Configs can point to different clusters.
+type Config struct {
+	conf *Configuration
+	// zeroKeys are the keys present in the loaded config file with zero value e.g. `debug: false`, which are
+	// used rather than the defaults. It is shared by the Configs made by WithLogSinks, so it is refilled on load.
+	zeroKeys                  map[string]bool
+	environmentKubeConfigPath *string
+	profile                   string
+
+	loggerMutex sync.Mutex
+	logger      *log.Logger
+	// logSinks, if not empty, are the sinks of logger instead of the configured ones
+	logSinks []io.Writer
+}
+
 const (
 	debugEnabledVal     = true
 	debugDisabledVal    = false
@@ -50,49 +216,178 @@ const (
 
 func init() {
 	defaultConf = Configuration{
-		Environment:    common.Minikube,
-		Debug:          debugDisabledVal,
-		KubeMasterURL:  "",
-		KubeConfigPath: filepath.Join(os.Getenv("HOME"), ".kube", "config"),
+		Environment:         common.Minikube,
+		Debug:               debugDisabledVal,
+		KubeMasterURL:       "",
+		KubeConfigPath:      filepath.Join(os.Getenv("HOME"), ".kube", "config"),
+		RunID:               generateRunID(),
+		ClusterReadyTimeout: 5 * time.Minute,
+		TeardownPolicy:      TeardownAlways,
+
+		Log: LogConfiguration{
+			Level:  "info",
+			Format: string(log.FormatText),
+			Sinks:  []string{"stderr"},
+		},
+
+		Kube: KubeConfiguration{
+			Context:           "",
+			QPS:               0,
+			Burst:             0,
+			Timeout:           0,
+			ImpersonateUser:   "",
+			ImpersonateGroups: []string{},
+		},
+
+		Kind: KindConfiguration{
+			ClusterName: "citf",
+			Nodes:       1,
+			NodeImage:   "",
+			Timeout:     5 * time.Minute,
+		},
+
+		Docker: DockerConfiguration{
+			SocketPath: "/var/run/docker.sock",
+		},
+
+		Minikube: MinikubeConfiguration{
+			Driver:            "none",
+			CPUs:              0,
+			Memory:            "",
+			KubernetesVersion: "",
+			FeatureGates:      []string{},
+			ExtraConfig:       []string{},
+			Profile:           "",
+			Timeout:           time.Minute,
+		},
+
+		Existing: ExistingConfiguration{
+			RequiredNamespaces: []string{},
+			RequiredCRDs:       []string{},
+		},
 	}
 
-	// Set debug status to util packages
-	SetDebugToUtilPackages(Debug())
+	// package level loggers write as per package level configurations
+	globalConfig.Logger()
+	globalConfig.reportInvalid()
 }
 
-// SetDebugToUtilPackages Enables/Disables debug in util packages
-// this way we are decoupling util packages from others
-func SetDebugToUtilPackages(debugEnabled bool) {
-	log.DebugEnabled = debugEnabled
+// generateRunID returns an ID which is unique for every run of CITF.
+// It is a valid label value of kubernetes as well as docker.
+func generateRunID() string {
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		logger.PrintError(err, "error generating random part of run ID")
+	}
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(randomBytes)
+}
+
+// NewConfig returns a new Config with configurations loaded from the file which path is supplied.
+// Empty path means only environment variables and default configurations are used.
+// Returned Config is usable (with default configuration) even if loading the file fails.
+// It doesn't change package level configuration, i.e. `Conf`.
+func NewConfig(confFilePath string) (*Config, error) {
+	return NewConfigForProfile(confFilePath, "")
+}
+
+// NewConfigForProfile is same as NewConfig except that configurations are loaded from the supplied profile
+// of the file. `CITF_PROFILE` takes precedence over the supplied profile.
+func NewConfigForProfile(confFilePath, profile string) (*Config, error) {
+	conf := &Config{
+		conf:                      &Configuration{},
+		zeroKeys:                  map[string]bool{},
+		environmentKubeConfigPath: new(string),
+	}
+	return conf, conf.LoadConfProfile(confFilePath, profile)
 }
 
-// LoadConf loads the configuration from the file which path is supplied
+// Global returns the Config which is used by the package level functions of this package
+// i.e. the one which reads from `Conf`
+func Global() *Config {
+	return globalConfig
+}
+
+// LoadConf loads the configuration from the file which path is supplied.
+// If the file has profiles, the one selected by `CITF_PROFILE` is loaded.
 func LoadConf(confFilePath string) error {
+	return LoadConfProfile(confFilePath, "")
+}
+
+// LoadConfProfile loads the configuration of the supplied profile from the file which path is supplied.
+// `CITF_PROFILE` takes precedence over the supplied profile.
+func LoadConfProfile(confFilePath, profile string) error {
+	return globalConfig.LoadConfProfile(confFilePath, profile)
+}
+
+// LoadConf loads the configuration of this Config from the file which path is supplied.
+// If the file has profiles, the one selected by `CITF_PROFILE` is loaded.
+func (conf *Config) LoadConf(confFilePath string) error {
+	return conf.LoadConfProfile(confFilePath, "")
+}
+
+// LoadConfProfile loads the configuration of this Config from the file which path is supplied.
+// File can either be a plain configuration or have shared `defaults` and named `profiles`,
+// where a profile can inherit another one. Values of a profile are merged over the ones it inherits
+// and then over `defaults`. Empty profile means only `defaults` are used.
+// `CITF_PROFILE` takes precedence over the supplied profile.
+// Keys which are not known are rejected along with their line numbers.
+// Every load starts afresh, i.e. nothing loaded earlier is kept. Values present in the file are used even if they
+// are zero values e.g. `debug: false` or `requiredNamespaces: []`.
+// Configuration of this Config is not changed if the file is not valid.
+// Invalid environment variables (`CITF_CONF_*`) are logged here, getters ignore them silently.
+func (conf *Config) LoadConfProfile(confFilePath, profile string) error {
 	if len(confFilePath) == 0 {
+		conf.reportInvalid()
 		return nil
 	}
+	profile = selectProfile(profile)
 
 	yamlBytes, err := ioutil.ReadFile(confFilePath)
 	if err != nil {
 		return fmt.Errorf("error reading file: %q. Error: %+v", confFilePath, err)
 	}
 
-	// Always pass pointer to the destination structure.
-	// https://github.com/go-yaml/yaml/issues/224
-	err = yaml.Unmarshal(yamlBytes, &Conf)
+	loaded, keys, err := parseConf(yamlBytes, profile)
 	if err != nil {
 		return fmt.Errorf("error parsing file: %q. Error: %+v", confFilePath, err)
 	}
+	if err = loaded.validate(); err != nil {
+		return fmt.Errorf("error in file: %q. Error: %+v", confFilePath, err)
+	}
 
-	// Set debug status to util packages
-	SetDebugToUtilPackages(Debug())
+	// every load replaces what an earlier load set, keys of another profile or file must not survive
+	*conf.conf = loaded
+	if conf.zeroKeys == nil {
+		conf.zeroKeys = map[string]bool{}
+	}
+	for key := range conf.zeroKeys {
+		delete(conf.zeroKeys, key)
+	}
+	loadedValue := reflect.ValueOf(loaded)
+	for _, s := range settings {
+		if keys[s.key] && isUnset(loadedValue.FieldByIndex(s.index)) {
+			conf.zeroKeys[s.key] = true
+		}
+	}
+	conf.profile = profile
+	conf.reloadLogger()
+	conf.reportInvalid()
 	return nil
 }
 
+// Profile returns the profile of the config file which is loaded, blank if none
+func (conf *Config) Profile() string {
+	return conf.profile
+}
+
 // getConfValueByStringField returns value of the given field string in given Configuration
+// zero value of the field (e.g. 0 for int) is returned as empty string, same as an empty string field.
+// Fields in sections are named by section followed by field e.g. "KindClusterName".
 func getConfValueByStringField(conf Configuration, field string) string {
-	r := reflect.ValueOf(conf)
-	f := reflect.Indirect(r).FieldByName(field)
+	f := fieldByName(reflect.ValueOf(conf), field)
+	if f.IsValid() && reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+		return ""
+	}
 	return fmt.Sprintf("%v", f)
 }
 
@@ -105,28 +400,73 @@ func GetDefaultValueByStringField(field string) string {
 // GetUserConfValueByStringField returns the value of the given field string in Default Configuration
 // fields should be in exact case as the field is present in struct Configuration
 func GetUserConfValueByStringField(field string) string {
-	return getConfValueByStringField(Conf, field)
+	return globalConfig.GetUserConfValueByStringField(field)
+}
+
+// GetUserConfValueByStringField returns the value of the given field string in the Configuration of this Config
+// fields should be in exact case as the field is present in struct Configuration
+func (conf *Config) GetUserConfValueByStringField(field string) string {
+	return getConfValueByStringField(*conf.conf, field)
 }
 
 // GetConf returns the applicable configuration for the given field
 func GetConf(field string) string {
+	return globalConfig.GetConf(field)
+}
+
+// GetConf returns the applicable configuration of this Config for the given field
+func (conf *Config) GetConf(field string) string {
 	if value, ok := os.LookupEnv("CITF_CONF_" + strings.ToUpper(field)); ok {
 		return value
 	}
-	if value := GetUserConfValueByStringField(field); len(value) != 0 {
+	if value := conf.GetUserConfValueByStringField(field); len(value) != 0 {
 		return value
 	}
 	return GetDefaultValueByStringField(field)
 }
 
+// GetConfList returns the applicable configuration for the given field which is a list of strings.
+// Value of environment variable is treated as comma separated list.
+func GetConfList(field string) []string {
+	return globalConfig.GetConfList(field)
+}
+
+// GetConfList returns the applicable configuration of this Config for the given field which is a list of strings.
+// Value of environment variable is treated as comma separated list.
+func (conf *Config) GetConfList(field string) []string {
+	if value, ok := os.LookupEnv("CITF_CONF_" + strings.ToUpper(field)); ok {
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	if value := fieldByName(reflect.ValueOf(*conf.conf), field); value.IsValid() && value.Len() != 0 {
+		return value.Interface().([]string)
+	}
+	return fieldByName(reflect.ValueOf(defaultConf), field).Interface().([]string)
+}
+
 // Environment returns the environment which should be used in testing
 func Environment() string {
-	return GetConf("Environment")
+	return globalConfig.Environment()
+}
+
+// Environment returns the environment which should be used in testing
+func (conf *Config) Environment() string {
+	return conf.effective().Environment
 }
 
 // Debug returns the environment which should be used in testing
 func Debug() bool {
-	return strings.ToLower(GetConf("Debug")) == debugEnabledValStr
+	return globalConfig.Debug()
+}
+
+// Debug returns whether debug is enabled
+func (conf *Config) Debug() bool {
+	return conf.effective().Debug
 }
 
 // Verbose is an alias of Debug which returns the environment which should be used in testing
@@ -134,10 +474,260 @@ var Verbose = Debug
 
 // KubeMasterURL returns the URL of kube-master as per citf configurations
 func KubeMasterURL() string {
-	return GetConf("KubeMasterURL")
+	return globalConfig.KubeMasterURL()
+}
+
+// KubeMasterURL returns the URL of kube-master as per citf configurations
+func (conf *Config) KubeMasterURL() string {
+	return conf.effective().KubeMasterURL
+}
+
+// SetEnvironmentKubeConfigPath sets the path of the kube-config which has been written by
+// the environment in use. It takes precedence over the default kube-config path
+// but not over the one supplied through environment variable or config file.
+func SetEnvironmentKubeConfigPath(kubeConfigPath string) {
+	globalConfig.SetEnvironmentKubeConfigPath(kubeConfigPath)
+}
+
+// SetEnvironmentKubeConfigPath sets the path of the kube-config which has been written by
+// the environment of this Config. It takes precedence over the default kube-config path
+// but not over the one supplied through environment variable or config file.
+func (conf *Config) SetEnvironmentKubeConfigPath(kubeConfigPath string) {
+	*conf.environmentKubeConfigPath = kubeConfigPath
 }
 
 // KubeConfigPath returns the path of kube-config as per citf configurations
 func KubeConfigPath() string {
-	return GetConf("KubeConfigPath")
+	return globalConfig.KubeConfigPath()
+}
+
+// KubeConfigPath returns the path of kube-config as per citf configurations
+func (conf *Config) KubeConfigPath() string {
+	return conf.effective().KubeConfigPath
+}
+
+// KindClusterName returns the name of the kind cluster as per citf configurations
+func KindClusterName() string {
+	return globalConfig.KindClusterName()
+}
+
+// KindClusterName returns the name of the kind cluster as per citf configurations
+func (conf *Config) KindClusterName() string {
+	return conf.effective().Kind.ClusterName
+}
+
+// KindNodes returns the number of nodes in kind cluster as per citf configurations
+func KindNodes() int {
+	return globalConfig.KindNodes()
+}
+
+// KindNodes returns the number of nodes in kind cluster as per citf configurations
+func (conf *Config) KindNodes() int {
+	return conf.effective().Kind.Nodes
+}
+
+// KindNodeImage returns the node image of the kind cluster as per citf configurations
+// Empty string means kind should use its own default image
+func KindNodeImage() string {
+	return globalConfig.KindNodeImage()
+}
+
+// KindNodeImage returns the node image of the kind cluster as per citf configurations
+// Empty string means kind should use its own default image
+func (conf *Config) KindNodeImage() string {
+	return conf.effective().Kind.NodeImage
+}
+
+// RunID returns the ID of the current run of CITF. Everything CITF creates is labelled with it
+// so that only those things are cleaned up. It can be supplied by CI e.g. job ID,
+// otherwise it is generated once per process.
+func RunID() string {
+	return globalConfig.RunID()
+}
+
+// RunID returns the ID of the current run of CITF. See package level RunID.
+func (conf *Config) RunID() string {
+	return conf.effective().RunID
+}
+
+// Kube returns the configurations of the client which talks to kubernetes e.g. context, QPS, impersonation
+func Kube() KubeConfiguration {
+	return globalConfig.Kube()
+}
+
+// Kube returns the configurations of the client which talks to kubernetes e.g. context, QPS, impersonation
+func (conf *Config) Kube() KubeConfiguration {
+	return conf.effective().Kube
+}
+
+// ClusterReadyTimeout returns the time to wait for the cluster to become ready after the setup of the environment
+func ClusterReadyTimeout() time.Duration {
+	return globalConfig.ClusterReadyTimeout()
+}
+
+// ClusterReadyTimeout returns the time to wait for the cluster to become ready after the setup of the environment
+func (conf *Config) ClusterReadyTimeout() time.Duration {
+	return conf.effective().ClusterReadyTimeout
+}
+
+// TeardownPolicy returns when the environment should be torn down i.e. one of TeardownAlways, TeardownNever and TeardownOnSuccess
+func TeardownPolicy() string {
+	return globalConfig.TeardownPolicy()
+}
+
+// TeardownPolicy returns when the environment should be torn down. See package level TeardownPolicy.
+func (conf *Config) TeardownPolicy() string {
+	return conf.effective().TeardownPolicy
+}
+
+// ShouldTeardown tells whether the environment should be torn down as per teardown policy,
+// `succeeded` tells whether the tests passed. Policy which is not known is treated as TeardownAlways.
+func (conf *Config) ShouldTeardown(succeeded bool) bool {
+	switch policy := conf.TeardownPolicy(); policy {
+	case TeardownNever:
+		return false
+	case TeardownOnSuccess:
+		return succeeded
+	case TeardownAlways:
+		return true
+	default:
+		conf.Logger().Warn("unknown teardown policy, treating it as "+TeardownAlways, "teardownPolicy", policy)
+		return true
+	}
+}
+
+// KindTimeout returns the timeout of the operations on kind cluster e.g. creating it
+func KindTimeout() time.Duration {
+	return globalConfig.KindTimeout()
+}
+
+// KindTimeout returns the timeout of the operations on kind cluster e.g. creating it
+func (conf *Config) KindTimeout() time.Duration {
+	return conf.effective().Kind.Timeout
+}
+
+// MinikubeTimeout returns the timeout of the operations on minikube e.g. waiting for its status
+func MinikubeTimeout() time.Duration {
+	return globalConfig.MinikubeTimeout()
+}
+
+// MinikubeTimeout returns the timeout of the operations on minikube e.g. waiting for its status
+func (conf *Config) MinikubeTimeout() time.Duration {
+	return conf.effective().Minikube.Timeout
+}
+
+// DockerSocketPath returns the path of the unix socket on which docker daemon listens
+func DockerSocketPath() string {
+	return globalConfig.DockerSocketPath()
+}
+
+// DockerSocketPath returns the path of the unix socket on which docker daemon listens
+func (conf *Config) DockerSocketPath() string {
+	return conf.effective().Docker.SocketPath
+}
+
+// MinikubeDriver returns the vm-driver with which minikube should be started
+func MinikubeDriver() string {
+	return globalConfig.MinikubeDriver()
+}
+
+// MinikubeDriver returns the vm-driver with which minikube should be started
+func (conf *Config) MinikubeDriver() string {
+	return conf.effective().Minikube.Driver
+}
+
+// MinikubeCPUs returns the number of CPUs allocated to minikube, 0 means minikube's default
+func MinikubeCPUs() int {
+	return globalConfig.MinikubeCPUs()
+}
+
+// MinikubeCPUs returns the number of CPUs allocated to minikube, 0 means minikube's default
+func (conf *Config) MinikubeCPUs() int {
+	return conf.effective().Minikube.CPUs
+}
+
+// MinikubeMemory returns the memory allocated to minikube e.g. "2048" or "2g", blank means minikube's default
+func MinikubeMemory() string {
+	return globalConfig.MinikubeMemory()
+}
+
+// MinikubeMemory returns the memory allocated to minikube e.g. "2048" or "2g", blank means minikube's default
+func (conf *Config) MinikubeMemory() string {
+	return conf.effective().Minikube.Memory
+}
+
+// MinikubeKubernetesVersion returns the kubernetes version minikube should run e.g. "v1.11.0",
+// blank means minikube's default
+func MinikubeKubernetesVersion() string {
+	return globalConfig.MinikubeKubernetesVersion()
+}
+
+// MinikubeKubernetesVersion returns the kubernetes version minikube should run e.g. "v1.11.0",
+// blank means minikube's default
+func (conf *Config) MinikubeKubernetesVersion() string {
+	return conf.effective().Minikube.KubernetesVersion
+}
+
+// MinikubeFeatureGates returns the feature gates which should be passed to minikube e.g. "MountPropagation=true"
+func MinikubeFeatureGates() []string {
+	return globalConfig.MinikubeFeatureGates()
+}
+
+// MinikubeFeatureGates returns the feature gates which should be passed to minikube e.g. "MountPropagation=true"
+func (conf *Config) MinikubeFeatureGates() []string {
+	return conf.effective().Minikube.FeatureGates
+}
+
+// MinikubeExtraConfig returns the extra configurations which should be passed to kubernetes components
+// through minikube e.g. "kubelet.max-pods=100"
+func MinikubeExtraConfig() []string {
+	return globalConfig.MinikubeExtraConfig()
+}
+
+// MinikubeExtraConfig returns the extra configurations which should be passed to kubernetes components
+// through minikube e.g. "kubelet.max-pods=100"
+func (conf *Config) MinikubeExtraConfig() []string {
+	return conf.effective().Minikube.ExtraConfig
+}
+
+// MinikubeProfile returns the name of the minikube profile, blank means minikube's default profile
+func MinikubeProfile() string {
+	return globalConfig.MinikubeProfile()
+}
+
+// MinikubeProfile returns the name of the minikube profile, blank means minikube's default profile
+func (conf *Config) MinikubeProfile() string {
+	return conf.effective().Minikube.Profile
+}
+
+// RunDir returns the directory where the files of the current run (e.g. kube-config) are kept
+func RunDir() string {
+	return globalConfig.RunDir()
+}
+
+// RunDir returns the directory where the files of the current run (e.g. kube-config) are kept
+func (conf *Config) RunDir() string {
+	return filepath.Join(os.TempDir(), "citf-"+conf.RunID())
+}
+
+// ExistingRequiredNamespaces returns the namespaces which must be present in the existing cluster
+func ExistingRequiredNamespaces() []string {
+	return globalConfig.ExistingRequiredNamespaces()
+}
+
+// ExistingRequiredNamespaces returns the namespaces which must be present in the existing cluster
+func (conf *Config) ExistingRequiredNamespaces() []string {
+	return conf.effective().Existing.RequiredNamespaces
+}
+
+// ExistingRequiredCRDs returns the names of the CustomResourceDefinitions (e.g. "disks.openebs.io")
+// which must be present in the existing cluster
+func ExistingRequiredCRDs() []string {
+	return globalConfig.ExistingRequiredCRDs()
+}
+
+// ExistingRequiredCRDs returns the names of the CustomResourceDefinitions (e.g. "disks.openebs.io")
+// which must be present in the existing cluster
+func (conf *Config) ExistingRequiredCRDs() []string {
+	return conf.effective().Existing.RequiredCRDs
 }
@@ -14,14 +14,16 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
-
-	"github.com/openebs/CITF/utils/log"
+	"time"
 )
 
-var logger log.Logger
-
 // CreateFile creates yaml file for test purpose
 func CreateFile() {
 	fileData1 := `
@@ -613,3 +615,434 @@ func TestKubeConfigPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConfList(t *testing.T) {
+	environContent, environSet := os.LookupEnv("CITF_CONF_EXISTINGREQUIREDNAMESPACES")
+	confBak := Conf
+	defer func() {
+		if environSet {
+			os.Setenv("CITF_CONF_EXISTINGREQUIREDNAMESPACES", environContent)
+		} else {
+			os.Unsetenv("CITF_CONF_EXISTINGREQUIREDNAMESPACES")
+		}
+		Conf = confBak
+	}()
+
+	os.Unsetenv("CITF_CONF_EXISTINGREQUIREDNAMESPACES")
+	Conf = Configuration{}
+	if got := GetConfList("ExistingRequiredNamespaces"); len(got) != 0 {
+		t.Errorf("GetConfList() with empty `Conf` = %q, want empty list", got)
+	}
+
+	Conf = Configuration{Existing: ExistingConfiguration{RequiredNamespaces: []string{"openebs"}}}
+	if got := GetConfList("ExistingRequiredNamespaces"); len(got) != 1 || got[0] != "openebs" {
+		t.Errorf("GetConfList() with list in `Conf` = %q, want %q", got, []string{"openebs"})
+	}
+
+	os.Setenv("CITF_CONF_EXISTINGREQUIREDNAMESPACES", "default, kube-system,,")
+	if got := GetConfList("ExistingRequiredNamespaces"); len(got) != 2 || got[0] != "default" || got[1] != "kube-system" {
+		t.Errorf("GetConfList() with environment variable set = %q, want %q", got, []string{"default", "kube-system"})
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	confFiles := map[string]string{
+		"./test-config-a.yaml": "kubeMasterURL: https://cluster-a:6443\nkubeConfigPath: /a/kubeconfig\n",
+		"./test-config-b.yaml": "kubeMasterURL: https://cluster-b:6443\nkubeConfigPath: /b/kubeconfig\n",
+	}
+	for path, data := range confFiles {
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("unable to create config file %q: %+v", path, err)
+		}
+		defer os.Remove(path)
+	}
+
+	globalConfBak := Conf
+	defer func() { Conf = globalConfBak }()
+	Conf = Configuration{KubeMasterURL: "https://global:6443"}
+
+	confA, err := NewConfig("./test-config-a.yaml")
+	if err != nil {
+		t.Fatalf("NewConfig() returned error: %+v", err)
+	}
+	confB, err := NewConfig("./test-config-b.yaml")
+	if err != nil {
+		t.Fatalf("NewConfig() returned error: %+v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "KubeMasterURL of first", got: confA.KubeMasterURL(), want: "https://cluster-a:6443"},
+		{name: "KubeMasterURL of second", got: confB.KubeMasterURL(), want: "https://cluster-b:6443"},
+		{name: "KubeConfigPath of first", got: confA.KubeConfigPath(), want: "/a/kubeconfig"},
+		{name: "KubeConfigPath of second", got: confB.KubeConfigPath(), want: "/b/kubeconfig"},
+		{name: "package level KubeMasterURL", got: KubeMasterURL(), want: "https://global:6443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	_, err = NewConfig("./not-present.yaml")
+	if err == nil {
+		t.Errorf("NewConfig() did not return error for missing file")
+	}
+}
+
+func TestLoadConfStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantErrText string
+	}{
+		{
+			name:        "unknown key at top level",
+			data:        "environment: kind\nkubeconfigpath: /a/kubeconfig\n",
+			wantErrText: "line 2: field kubeconfigpath not found",
+		},
+		{
+			name:        "unknown key in section",
+			data:        "kind:\n  clusterName: citf\n  node: 3\n",
+			wantErrText: "line 3: field node not found",
+		},
+		{
+			name:        "bad duration",
+			data:        "clusterReadyTimeout: 5 minutes\n",
+			wantErrText: "line 1",
+		},
+		{
+			name:        "negative number of nodes",
+			data:        "kind:\n  nodes: -1\n",
+			wantErrText: "kind.nodes must not be negative",
+		},
+		{
+			name:        "unknown teardown policy",
+			data:        "teardownPolicy: sometimes\n",
+			wantErrText: "teardownPolicy must be one of",
+		},
+		{
+			name:        "unknown log level",
+			data:        "log:\n  level: verbose\n",
+			wantErrText: "log.level",
+		},
+		{
+			name: "valid",
+			data: "clusterReadyTimeout: 10m\nteardownPolicy: on-success\nlog:\n  level: debug\n  format: json\nkind:\n  nodes: 3\nminikube:\n  featureGates: [MountPropagation=true]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ioutil.WriteFile("./test-strict-config.yaml", []byte(tt.data), 0644); err != nil {
+				t.Fatalf("unable to create config file: %+v", err)
+			}
+			defer os.Remove("./test-strict-config.yaml")
+
+			_, err := NewConfig("./test-strict-config.yaml")
+			if tt.wantErrText == "" && err != nil {
+				t.Errorf("NewConfig() returned error: %+v", err)
+			}
+			if tt.wantErrText != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErrText)) {
+				t.Errorf("NewConfig() error = %v, want error containing %q", err, tt.wantErrText)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	for _, envVar := range []string{"CITF_CONF_KINDNODES", "CITF_CONF_MINIKUBETIMEOUT", "CITF_CONF_MINIKUBEEXTRACONFIG", "CITF_CONF_DEBUG"} {
+		environContent, environSet := os.LookupEnv(envVar)
+		if environSet {
+			defer os.Setenv(envVar, environContent)
+		} else {
+			defer os.Unsetenv(envVar)
+		}
+	}
+	os.Unsetenv("CITF_CONF_KINDNODES")
+	os.Unsetenv("CITF_CONF_DEBUG")
+	os.Setenv("CITF_CONF_MINIKUBETIMEOUT", "90s")
+	os.Setenv("CITF_CONF_MINIKUBEEXTRACONFIG", "kubelet.max-pods=100, apiserver.v=2")
+
+	logs := &bytes.Buffer{}
+	conf := &Config{
+		conf: &Configuration{
+			Kind: KindConfiguration{Nodes: 3},
+		},
+		environmentKubeConfigPath: new(string),
+		logSinks:                  []io.Writer{logs},
+	}
+	conf.SetEnvironmentKubeConfigPath("/run/kind.kubeconfig")
+
+	effective, err := conf.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %+v", err)
+	}
+	if effective.Minikube.Timeout != 90*time.Second {
+		t.Errorf("Resolve().Minikube.Timeout = %v, want %v", effective.Minikube.Timeout, 90*time.Second)
+	}
+	if want := []string{"kubelet.max-pods=100", "apiserver.v=2"}; !reflect.DeepEqual(effective.Minikube.ExtraConfig, want) {
+		t.Errorf("Resolve().Minikube.ExtraConfig = %q, want %q", effective.Minikube.ExtraConfig, want)
+	}
+
+	values, err := conf.Values()
+	if err != nil {
+		t.Fatalf("Values() returned error: %+v", err)
+	}
+	sources := map[string]Source{}
+	for _, value := range values {
+		sources[value.Key] = value.Source
+	}
+	wantSources := map[string]Source{
+		"environment":       SourceDefault,
+		"kubeConfigPath":    SourceEnvironment,
+		"kind.nodes":        SourceFile,
+		"minikube.timeout":  SourceEnv,
+		"docker.socketPath": SourceDefault,
+	}
+	for key, want := range wantSources {
+		if sources[key] != want {
+			t.Errorf("source of %q = %q, want %q", key, sources[key], want)
+		}
+	}
+
+	// invalid environment variable is ignored, and reported only when configuration is loaded
+	os.Setenv("CITF_CONF_KINDNODES", "three")
+	if conf.KindNodes() != 3 {
+		t.Errorf("KindNodes() = %d with invalid environment variable, want value from file %d", conf.KindNodes(), 3)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("getter logged %q, want nothing", logs.String())
+	}
+	if err = conf.LoadConf(""); err != nil || strings.Count(logs.String(), "CITF_CONF_KINDNODES") != 1 {
+		t.Errorf("LoadConf() returned %v and logged %q, want invalid CITF_CONF_KINDNODES reported once", err, logs.String())
+	}
+	var dump bytes.Buffer
+	if err = conf.Dump(&dump); err == nil || !strings.Contains(err.Error(), "CITF_CONF_KINDNODES") {
+		t.Errorf("Dump() error = %v, want error about CITF_CONF_KINDNODES", err)
+	}
+	if !strings.Contains(dump.String(), "env (CITF_CONF_MINIKUBETIMEOUT)") {
+		t.Errorf("Dump() = %q, want it to show source of minikube.timeout", dump.String())
+	}
+}
+
+func TestLoadConfProfile(t *testing.T) {
+	profilesData := `
+defaults:
+  debug: true
+  kind:
+    clusterName: citf
+    nodes: 1
+profiles:
+  local:
+    environment: minikube
+  shared:
+    environment: existing
+    existing:
+      requiredNamespaces: [openebs]
+  ci:
+    inherits: shared
+    kind:
+      nodes: 3
+  loop-a:
+    inherits: loop-b
+  loop-b:
+    inherits: loop-a
+`
+	if err := ioutil.WriteFile("./test-profiles-config.yaml", []byte(profilesData), 0644); err != nil {
+		t.Fatalf("unable to create config file: %+v", err)
+	}
+	defer os.Remove("./test-profiles-config.yaml")
+
+	environContent, environSet := os.LookupEnv(ProfileEnvVar)
+	if environSet {
+		defer os.Setenv(ProfileEnvVar, environContent)
+	} else {
+		defer os.Unsetenv(ProfileEnvVar)
+	}
+
+	tests := []struct {
+		name        string
+		profile     string
+		envProfile  string
+		want        Configuration
+		wantErrText string
+	}{
+		{
+			name:    "defaults only",
+			profile: "",
+			want:    Configuration{Debug: true, Kind: KindConfiguration{ClusterName: "citf", Nodes: 1}},
+		},
+		{
+			name:    "profile over defaults",
+			profile: "local",
+			want:    Configuration{Environment: "minikube", Debug: true, Kind: KindConfiguration{ClusterName: "citf", Nodes: 1}},
+		},
+		{
+			name:    "inherited profile merges nested values",
+			profile: "ci",
+			want: Configuration{
+				Environment: "existing",
+				Debug:       true,
+				Kind:        KindConfiguration{ClusterName: "citf", Nodes: 3},
+				Existing:    ExistingConfiguration{RequiredNamespaces: []string{"openebs"}},
+			},
+		},
+		{
+			name:       "environment variable takes precedence",
+			profile:    "local",
+			envProfile: "shared",
+			want: Configuration{
+				Environment: "existing",
+				Debug:       true,
+				Kind:        KindConfiguration{ClusterName: "citf", Nodes: 1},
+				Existing:    ExistingConfiguration{RequiredNamespaces: []string{"openebs"}},
+			},
+		},
+		{
+			name:        "profile not present",
+			profile:     "staging",
+			wantErrText: "profiles present are: [ci, local, loop-a, loop-b, shared]",
+		},
+		{
+			name:        "inheritance loop",
+			profile:     "loop-a",
+			wantErrText: "inherits itself",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envProfile != "" {
+				os.Setenv(ProfileEnvVar, tt.envProfile)
+			} else {
+				os.Unsetenv(ProfileEnvVar)
+			}
+
+			conf, err := NewConfigForProfile("./test-profiles-config.yaml", tt.profile)
+			if tt.wantErrText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Errorf("NewConfigForProfile() error = %v, want error containing %q", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewConfigForProfile() returned error: %+v", err)
+			}
+			if !reflect.DeepEqual(*conf.conf, tt.want) {
+				t.Errorf("NewConfigForProfile() loaded %+v, want %+v", *conf.conf, tt.want)
+			}
+		})
+	}
+
+	os.Unsetenv(ProfileEnvVar)
+	CreateFile()
+	defer DeleteFile()
+	if _, err := NewConfigForProfile("./test-config.yaml", "ci"); err == nil {
+		t.Errorf("NewConfigForProfile() did not return error for profile of plain config file")
+	}
+}
+
+func TestLoadConfProfileZeroValues(t *testing.T) {
+	profilesData := `
+defaults:
+  debug: true
+  environment: kind
+  kind:
+    nodes: 3
+  existing:
+    requiredNamespaces: [openebs]
+profiles:
+  bare:
+    debug: false
+    kind:
+      nodes: 0
+    existing:
+      requiredNamespaces: []
+  local:
+    environment: minikube
+`
+	if err := ioutil.WriteFile("./test-zero-values-config.yaml", []byte(profilesData), 0644); err != nil {
+		t.Fatalf("unable to create config file: %+v", err)
+	}
+	defer os.Remove("./test-zero-values-config.yaml")
+
+	environContent, environSet := os.LookupEnv(ProfileEnvVar)
+	if environSet {
+		defer os.Setenv(ProfileEnvVar, environContent)
+	} else {
+		defer os.Unsetenv(ProfileEnvVar)
+	}
+	os.Unsetenv(ProfileEnvVar)
+
+	conf, err := NewConfigForProfile("./test-zero-values-config.yaml", "bare")
+	if err != nil {
+		t.Fatalf("NewConfigForProfile() returned error: %+v", err)
+	}
+	if conf.Debug() || conf.KindNodes() != 0 || len(conf.ExistingRequiredNamespaces()) != 0 {
+		t.Errorf("zero values of profile are not used: Debug() = %t, KindNodes() = %d, ExistingRequiredNamespaces() = %v",
+			conf.Debug(), conf.KindNodes(), conf.ExistingRequiredNamespaces())
+	}
+	if conf.Environment() != "kind" {
+		t.Errorf("Environment() = %q, want %q from defaults", conf.Environment(), "kind")
+	}
+
+	// reloading with another profile keeps nothing of the earlier one
+	if err = conf.LoadConfProfile("./test-zero-values-config.yaml", "local"); err != nil {
+		t.Fatalf("LoadConfProfile() returned error: %+v", err)
+	}
+	if !conf.Debug() || conf.KindNodes() != 3 || !reflect.DeepEqual(conf.ExistingRequiredNamespaces(), []string{"openebs"}) {
+		t.Errorf("values of profile bare survived the reload: Debug() = %t, KindNodes() = %d, ExistingRequiredNamespaces() = %v",
+			conf.Debug(), conf.KindNodes(), conf.ExistingRequiredNamespaces())
+	}
+	if conf.Environment() != "minikube" {
+		t.Errorf("Environment() = %q, want %q", conf.Environment(), "minikube")
+	}
+
+	conf.LoadConfProfile("./test-zero-values-config.yaml", "bare")
+	CreateFile()
+	defer DeleteFile()
+	if err = conf.LoadConf("./test-config.yaml"); err != nil {
+		t.Fatalf("LoadConf() returned error: %+v", err)
+	}
+	if conf.KindNodes() != defaultConf.Kind.Nodes {
+		t.Errorf("KindNodes() = %d after loading a file without it, want default %d", conf.KindNodes(), defaultConf.Kind.Nodes)
+	}
+}
+
+func TestWithLogSinks(t *testing.T) {
+	path := "./test-config-log.yaml"
+	if err := ioutil.WriteFile(path, []byte("kubeMasterURL: https://cluster-a:6443\nlog:\n  level: warn\n  format: json\n"), 0644); err != nil {
+		t.Fatalf("unable to create config file %q: %+v", path, err)
+	}
+	defer os.Remove(path)
+
+	conf, err := NewConfig(path)
+	if err != nil {
+		t.Fatalf("NewConfig() returned error: %+v", err)
+	}
+	buf := &bytes.Buffer{}
+	scoped := conf.WithLogSinks(buf)
+
+	if scoped.KubeMasterURL() != conf.KubeMasterURL() {
+		t.Errorf("KubeMasterURL() of Config with log sinks = %q, want %q", scoped.KubeMasterURL(), conf.KubeMasterURL())
+	}
+	scoped.Logger().Info("not written")
+	scoped.Logger().Warn("written")
+	if strings.Contains(buf.String(), "not written") || !strings.Contains(buf.String(), `"msg":"written"`) {
+		t.Errorf("logger of Config with log sinks wrote %q, want only the warn record in JSON", buf.String())
+	}
+
+	// loading configuration again keeps the sinks
+	if err = scoped.LoadConf(path); err != nil {
+		t.Fatalf("LoadConf() returned error: %+v", err)
+	}
+	buf.Reset()
+	scoped.Logger().Error("written again")
+	if !strings.Contains(buf.String(), "written again") {
+		t.Errorf("logger of Config with log sinks wrote %q after reloading, want the error record", buf.String())
+	}
+}
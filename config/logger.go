@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/openebs/CITF/utils/log"
+)
+
+var (
+	// sinkFiles are the files opened as sinks of loggers, so that a file is opened once however many loggers use it
+	sinkFiles      = map[string]*os.File{}
+	sinkFilesMutex sync.Mutex
+)
+
+// openSink returns the writer for the sink supplied i.e. stdout, stderr or path of a file which is appended to
+func openSink(sink string) (io.Writer, error) {
+	switch sink {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	sinkFilesMutex.Lock()
+	defer sinkFilesMutex.Unlock()
+	if file, ok := sinkFiles[sink]; ok {
+		return file, nil
+	}
+	file, err := os.OpenFile(sink, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	sinkFiles[sink] = file
+	return file, nil
+}
+
+// LogOptions returns the options of the logger as per `log` section and `debug` of this Config.
+// Sinks given to WithLogSinks, if any, are used instead of `log.sinks`.
+// Invalid level is treated as info and sinks which can not be opened are reported on stderr and skipped.
+func (conf *Config) LogOptions() log.Options {
+	effective := conf.effective()
+
+	level, err := log.ParseLevel(effective.Log.Level)
+	if err != nil {
+		log.New(log.Options{}).Error("using log level info", "error", err)
+	}
+	if effective.Debug {
+		level = log.LevelDebug
+	}
+
+	sinks := conf.logSinks
+	if len(sinks) == 0 {
+		for _, sink := range effective.Log.Sinks {
+			writer, err := openSink(sink)
+			if err != nil {
+				log.New(log.Options{}).Error("skipping log sink", "sink", sink, "error", err)
+				continue
+			}
+			sinks = append(sinks, writer)
+		}
+	}
+
+	return log.Options{
+		Level:  level,
+		Format: log.Format(effective.Log.Format),
+		Sinks:  sinks,
+	}
+}
+
+// Logger returns the logger of this Config, which writes as per its LogOptions.
+// It is created once, so everything which uses this Config (e.g. CITF, K8S and Environment) writes through it.
+// Logger of package level configuration is the zero value of log.Logger i.e. that of package level loggers.
+func (conf *Config) Logger() log.Logger {
+	conf.loggerMutex.Lock()
+	defer conf.loggerMutex.Unlock()
+
+	if conf.logger == nil {
+		logger := log.Logger{}
+		if conf != globalConfig {
+			logger = log.New(log.Options{})
+		}
+		logger.SetOptions(conf.LogOptions())
+		conf.logger = &logger
+	}
+	return *conf.logger
+}
+
+// reloadLogger makes logger of this Config, if created, write as per its current configuration
+func (conf *Config) reloadLogger() {
+	conf.loggerMutex.Lock()
+	defer conf.loggerMutex.Unlock()
+
+	if conf.logger != nil {
+		conf.logger.SetOptions(conf.LogOptions())
+	}
+}
+
+// WithLogSinks returns a Config which reads the same configurations as conf (loading either changes both)
+// but which logger writes only to sinks, e.g. to the output of a test (see log.TestWriter) or GinkgoWriter.
+// Level and format of its logger are still as per the configuration.
+func (conf *Config) WithLogSinks(sinks ...io.Writer) *Config {
+	return &Config{
+		conf:                      conf.conf,
+		zeroKeys:                  conf.zeroKeys,
+		environmentKubeConfigPath: conf.environmentKubeConfigPath,
+		profile:                   conf.profile,
+		logSinks:                  sinks,
+	}
+}
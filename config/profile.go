@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ProfileEnvVar is the environment variable which selects the profile of the config file.
+// It takes precedence over the profile supplied in code, same as other environment variables.
+const ProfileEnvVar = "CITF_PROFILE"
+
+// profileConfiguration is the configuration of a profile in config file
+type profileConfiguration struct {
+	// Inherits is the name of the profile this profile is based on, blank means `defaults` only
+	Inherits      string `yaml:"inherits,omitempty"`
+	Configuration `yaml:",inline"`
+}
+
+// profilesFile is the config file which has multiple named profiles and shared defaults e.g.
+//
+//	defaults:
+//	  debug: true
+//	profiles:
+//	  local:
+//	    environment: minikube
+//	  ci:
+//	    environment: existing
+type profilesFile struct {
+	Defaults Configuration                   `yaml:"defaults,omitempty"`
+	Profiles map[string]profileConfiguration `yaml:"profiles,omitempty"`
+
+	// defaultKeys and profileKeys are the keys present in `defaults` and in each profile, see presentKeys
+	defaultKeys map[string]bool
+	profileKeys map[string]map[string]bool
+}
+
+// selectProfile returns the profile which should be used, `CITF_PROFILE` takes precedence over supplied one
+func selectProfile(profile string) string {
+	if value, ok := os.LookupEnv(ProfileEnvVar); ok && value != "" {
+		return value
+	}
+	return profile
+}
+
+// isProfilesFile returns whether the yaml has `defaults` or `profiles` at the top level
+func isProfilesFile(yamlBytes []byte) bool {
+	var topLevel map[string]interface{}
+	if err := yaml.Unmarshal(yamlBytes, &topLevel); err != nil {
+		// let the strict parsing of plain config file report the error
+		return false
+	}
+	_, hasDefaults := topLevel["defaults"]
+	_, hasProfiles := topLevel["profiles"]
+	return hasDefaults || hasProfiles
+}
+
+// presentKeys returns the keys of the configurations which are present in the parsed yaml node,
+// including the ones with zero value e.g. `debug: false`, so that those can override other values.
+// Keys with null value are not considered present.
+func presentKeys(node interface{}) map[string]bool {
+	keys := map[string]bool{}
+	for _, s := range settings {
+		if hasKey(node, strings.Split(s.key, ".")) {
+			keys[s.key] = true
+		}
+	}
+	return keys
+}
+
+// parseTree returns the yaml, which is already parsed strictly, as generic mapping
+func parseTree(yamlBytes []byte) map[interface{}]interface{} {
+	var tree map[interface{}]interface{}
+	yaml.Unmarshal(yamlBytes, &tree)
+	return tree
+}
+
+// hasKey returns whether the parsed yaml node has a non-null value at the path e.g. ["kind", "nodes"]
+func hasKey(node interface{}, path []string) bool {
+	mapping, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	value, ok := mapping[path[0]]
+	if !ok || value == nil {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	return hasKey(value, path[1:])
+}
+
+// mergeConfiguration returns base with every value which is present in override, as per overrideKeys, replaced.
+// Values are merged one by one even in sections, lists are replaced as a whole.
+func mergeConfiguration(base, override Configuration, overrideKeys map[string]bool) Configuration {
+	merged := base
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	overrideValue := reflect.ValueOf(override)
+	for _, s := range settings {
+		if overrideKeys[s.key] {
+			mergedValue.FieldByIndex(s.index).Set(overrideValue.FieldByIndex(s.index))
+		}
+	}
+	return merged
+}
+
+// profileNames returns sorted names of the profiles in the file
+func (file profilesFile) profileNames() []string {
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveProfile returns the configuration of the supplied profile merged over
+// the profiles it inherits and then over `defaults`, along with the keys present in any of them.
+// Empty profile means `defaults` only.
+func (file profilesFile) resolveProfile(profile string) (Configuration, map[string]bool, error) {
+	// chain is the names of the profiles from the supplied one up to the one which inherits nothing
+	var chain []string
+	visited := map[string]bool{}
+	for name := profile; name != ""; {
+		if visited[name] {
+			return Configuration{}, nil, fmt.Errorf("profile %q inherits itself through %q", profile, name)
+		}
+		visited[name] = true
+
+		profileConf, ok := file.Profiles[name]
+		if !ok {
+			return Configuration{}, nil, fmt.Errorf("profile %q is not present, profiles present are: [%s]", name, strings.Join(file.profileNames(), ", "))
+		}
+		chain = append(chain, name)
+		name = profileConf.Inherits
+	}
+
+	resolved := file.Defaults
+	keys := map[string]bool{}
+	for key := range file.defaultKeys {
+		keys[key] = true
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		profileKeys := file.profileKeys[chain[i]]
+		resolved = mergeConfiguration(resolved, file.Profiles[chain[i]].Configuration, profileKeys)
+		for key := range profileKeys {
+			keys[key] = true
+		}
+	}
+	return resolved, keys, nil
+}
+
+// parseConf parses the config file content for the supplied profile. It returns the configuration
+// along with the keys present in the file for it (see presentKeys). Plain config file can't be used with a profile.
+func parseConf(yamlBytes []byte, profile string) (Configuration, map[string]bool, error) {
+	if !isProfilesFile(yamlBytes) {
+		if profile != "" {
+			return Configuration{}, nil, fmt.Errorf("profile %q is selected but file has no profiles", profile)
+		}
+		var configuration Configuration
+		if err := yaml.UnmarshalStrict(yamlBytes, &configuration); err != nil {
+			return Configuration{}, nil, err
+		}
+		return configuration, presentKeys(parseTree(yamlBytes)), nil
+	}
+
+	var file profilesFile
+	if err := yaml.UnmarshalStrict(yamlBytes, &file); err != nil {
+		return Configuration{}, nil, err
+	}
+	tree := parseTree(yamlBytes)
+	file.defaultKeys = presentKeys(tree["defaults"])
+	file.profileKeys = map[string]map[string]bool{}
+	profiles, _ := tree["profiles"].(map[interface{}]interface{})
+	for name := range file.Profiles {
+		file.profileKeys[name] = presentKeys(profiles[name])
+	}
+	return file.resolveProfile(profile)
+}
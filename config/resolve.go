@@ -0,0 +1,245 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Source tells from where the effective value of a configuration came
+type Source string
+
+const (
+	// SourceEnv means value came from the environment variable of the configuration
+	SourceEnv Source = "env"
+	// SourceFile means value came from the config file (or `Conf` if it is set directly)
+	SourceFile Source = "file"
+	// SourceEnvironment means value was set by the environment in use e.g. kube-config written by kind
+	SourceEnvironment Source = "environment"
+	// SourceDefault means value is the default one
+	SourceDefault Source = "default"
+)
+
+// Value is the effective value of a configuration along with its source
+type Value struct {
+	// Key is the path of the configuration in config file e.g. "kind.clusterName"
+	Key string
+	// EnvVar is the name of the environment variable which overrides the configuration
+	EnvVar string
+	// Value is the effective value
+	Value interface{}
+	// Source is from where Value came
+	Source Source
+}
+
+// setting describes a configuration which has a value i.e. which is not a section
+type setting struct {
+	key   string
+	field string
+	index []int
+}
+
+// settings are all the configurations in the order of their declaration in Configuration
+var settings = collectSettings(reflect.TypeOf(Configuration{}), "", "", nil)
+
+// collectSettings returns settings of all the fields of supplied struct type, recursing into sections.
+// Name of the field of a setting is the name of the section followed by the name of the field
+// e.g. "KindClusterName", so that environment variables are same as they were before sections.
+func collectSettings(structType reflect.Type, keyPrefix, fieldPrefix string, index []int) []setting {
+	var collected []setting
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		key := keyPrefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			collected = append(collected, collectSettings(field.Type, key+".", fieldPrefix+field.Name, fieldIndex)...)
+			continue
+		}
+		collected = append(collected, setting{key: key, field: fieldPrefix + field.Name, index: fieldIndex})
+	}
+	return collected
+}
+
+// envVar returns the name of the environment variable which overrides the setting
+func (s setting) envVar() string {
+	return "CITF_CONF_" + strings.ToUpper(s.field)
+}
+
+// fieldByName returns the field of supplied Configuration value for the name of a setting e.g. "KindClusterName".
+// Returned value is invalid if there is no such field.
+func fieldByName(conf reflect.Value, field string) reflect.Value {
+	if value := conf.FieldByName(field); value.IsValid() {
+		return value
+	}
+	for _, s := range settings {
+		if s.field == field {
+			return conf.FieldByIndex(s.index)
+		}
+	}
+	return reflect.Value{}
+}
+
+// isUnset returns whether the value is zero value of its type, empty list is also considered unset
+func isUnset(value reflect.Value) bool {
+	if value.Kind() == reflect.Slice {
+		return value.Len() == 0
+	}
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+// parseValue parses the string (i.e. value of an environment variable) as per the type supplied.
+// Lists are comma separated.
+func parseValue(str string, valueType reflect.Type) (reflect.Value, error) {
+	switch valueType {
+	case reflect.TypeOf(time.Duration(0)):
+		duration, err := time.ParseDuration(str)
+		return reflect.ValueOf(duration), err
+	case reflect.TypeOf([]string{}):
+		list := []string{}
+		for _, item := range strings.Split(str, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return reflect.ValueOf(list), nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(str).Convert(valueType), nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		return reflect.ValueOf(b), err
+	case reflect.Int:
+		i, err := strconv.Atoi(str)
+		return reflect.ValueOf(i), err
+	case reflect.Float32:
+		f, err := strconv.ParseFloat(str, 32)
+		return reflect.ValueOf(float32(f)), err
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported type %v", valueType)
+}
+
+// resolve returns effective configuration and the values with their source.
+// Precedence is environment variable, then Configuration of this Config (its zero values only if
+// present in the config file), then
+// kube-config set by the environment (only for kube-config path), then default.
+// If an environment variable can not be parsed, it is ignored and error is returned along with the result.
+func (conf *Config) resolve() (Configuration, []Value, error) {
+	effective := Configuration{}
+	effectiveValue := reflect.ValueOf(&effective).Elem()
+	userValue := reflect.ValueOf(*conf.conf)
+	defaultValue := reflect.ValueOf(defaultConf)
+
+	values := make([]Value, 0, len(settings))
+	var problems []string
+	for _, s := range settings {
+		out := effectiveValue.FieldByIndex(s.index)
+		source := SourceDefault
+		out.Set(defaultValue.FieldByIndex(s.index))
+
+		if user := userValue.FieldByIndex(s.index); !isUnset(user) || conf.zeroKeys[s.key] {
+			out.Set(user)
+			source = SourceFile
+		} else if s.field == "KubeConfigPath" && len(*conf.environmentKubeConfigPath) != 0 {
+			out.SetString(*conf.environmentKubeConfigPath)
+			source = SourceEnvironment
+		}
+
+		if str, ok := os.LookupEnv(s.envVar()); ok {
+			parsed, err := parseValue(str, out.Type())
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("invalid value %q in %s for %s: %v", str, s.envVar(), s.key, err))
+			} else {
+				out.Set(parsed)
+				source = SourceEnv
+			}
+		}
+
+		values = append(values, Value{Key: s.key, EnvVar: s.envVar(), Value: out.Interface(), Source: source})
+	}
+
+	if len(problems) != 0 {
+		return effective, values, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return effective, values, nil
+}
+
+// effective returns effective configuration. Invalid environment variables are ignored silently,
+// they are reported once when the configuration is loaded (see reportInvalid).
+func (conf *Config) effective() Configuration {
+	effective, _, _ := conf.resolve()
+	return effective
+}
+
+// reportInvalid logs the invalid environment variables which are ignored, it is called when the configuration is loaded
+func (conf *Config) reportInvalid() {
+	_, _, err := conf.resolve()
+	conf.Logger().PrintError(err, "ignoring invalid configuration")
+}
+
+// Resolve returns effective configuration of this Config with typed values.
+// Invalid environment variables are ignored and reported in the returned error.
+func (conf *Config) Resolve() (Configuration, error) {
+	effective, _, err := conf.resolve()
+	return effective, err
+}
+
+// Values returns effective value of every configuration along with its source
+// in the order they are declared in Configuration.
+// Invalid environment variables are ignored and reported in the returned error.
+func (conf *Config) Values() ([]Value, error) {
+	_, values, err := conf.resolve()
+	return values, err
+}
+
+// Dump writes effective value of every configuration along with its source to w, one per line.
+// It is meant to be printed in CI logs so that it is known which configuration was in effect.
+func (conf *Config) Dump(w io.Writer) error {
+	values, resolveErr := conf.Values()
+
+	if conf.profile != "" {
+		fmt.Fprintf(w, "profile: %s\n", conf.profile)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, value := range values {
+		source := string(value.Source)
+		if value.Source == SourceEnv {
+			source += " (" + value.EnvVar + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", value.Key, value.Value, source)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return resolveErr
+}
+
+// Values returns effective value of every package level configuration along with its source
+func Values() ([]Value, error) {
+	return globalConfig.Values()
+}
+
+// Dump writes effective value of every package level configuration along with its source to w
+func Dump(w io.Writer) error {
+	return globalConfig.Dump(w)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Version is the version information of the docker daemon
+type Version struct {
+	Version    string `json:"Version"`
+	APIVersion string `json:"ApiVersion"`
+	Os         string `json:"Os"`
+	Arch       string `json:"Arch"`
+}
+
+// RunOptions are the options to run a container
+type RunOptions struct {
+	// Name of the container, blank means docker chooses it
+	Name   string
+	Image  string
+	Cmd    []string
+	Env    []string
+	Labels map[string]string
+}
+
+// ContainerConfig is the configuration of a container
+type ContainerConfig struct {
+	Image  string            `json:"Image"`
+	Cmd    []string          `json:"Cmd"`
+	Env    []string          `json:"Env"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// ContainerState is the state of a container
+type ContainerState struct {
+	Status     string `json:"Status"`
+	Running    bool   `json:"Running"`
+	Paused     bool   `json:"Paused"`
+	ExitCode   int    `json:"ExitCode"`
+	Error      string `json:"Error"`
+	StartedAt  string `json:"StartedAt"`
+	FinishedAt string `json:"FinishedAt"`
+}
+
+// Container is the detailed information of a container as returned by inspect
+type Container struct {
+	ID      string          `json:"Id"`
+	Name    string          `json:"Name"`
+	Created string          `json:"Created"`
+	Config  ContainerConfig `json:"Config"`
+	State   ContainerState  `json:"State"`
+}
+
+// ContainerSummary is the information of a container as returned by list
+type ContainerSummary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// ExecResult is the result of a command executed in a container
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// Logs are the logs of a container
+type Logs struct {
+	Stdout string
+	Stderr string
+}
+
+// Ping returns error if docker daemon is not healthy
+func (client *Client) Ping() error {
+	resp, err := client.do(http.MethodGet, "/_ping", nil, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Version returns the version information of the docker daemon
+func (client *Client) Version() (Version, error) {
+	var version Version
+	err := client.doJSON(http.MethodGet, "/version", nil, nil, &version, http.StatusOK)
+	return version, err
+}
+
+// ContainerRun creates a container and starts it, then returns the ID of the container.
+// Container is labelled with the current run ID in addition to the supplied labels.
+func (client *Client) ContainerRun(options RunOptions) (string, error) {
+	labels := map[string]string{}
+	for key, value := range options.Labels {
+		labels[key] = value
+	}
+	labels[runLabelKey()] = client.runLabelValue()
+
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := client.doJSON(http.MethodPost, "/containers/create", query, ContainerConfig{
+		Image:  options.Image,
+		Cmd:    options.Cmd,
+		Env:    options.Env,
+		Labels: labels,
+	}, &created, http.StatusCreated)
+	if err != nil {
+		return "", fmt.Errorf("error creating container from image %q. Error: %+v", options.Image, err)
+	}
+
+	err = client.doJSON(http.MethodPost, "/containers/"+created.ID+"/start", nil, nil, nil, http.StatusNoContent, http.StatusNotModified)
+	if err != nil {
+		return created.ID, fmt.Errorf("error starting container %q. Error: %+v", created.ID, err)
+	}
+	return created.ID, nil
+}
+
+// ContainerExec executes the command in the running container and returns its output and exit code
+func (client *Client) ContainerExec(containerID string, cmd []string) (ExecResult, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := client.doJSON(http.MethodPost, "/containers/"+containerID+"/exec", nil, map[string]interface{}{
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"Cmd":          cmd,
+	}, &created, http.StatusCreated)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("error creating exec in container %q. Error: %+v", containerID, err)
+	}
+
+	resp, err := client.do(http.MethodPost, "/exec/"+created.ID+"/start", nil, map[string]bool{
+		"Detach": false,
+		"Tty":    false,
+	}, http.StatusOK)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("error starting exec %q. Error: %+v", created.ID, err)
+	}
+	defer resp.Body.Close()
+
+	var result ExecResult
+	result.Stdout, result.Stderr, err = demultiplexStream(resp.Body)
+	if err != nil {
+		return result, err
+	}
+
+	var inspect struct {
+		ExitCode int `json:"ExitCode"`
+	}
+	err = client.doJSON(http.MethodGet, "/exec/"+created.ID+"/json", nil, nil, &inspect, http.StatusOK)
+	result.ExitCode = inspect.ExitCode
+	return result, err
+}
+
+// ContainerLogs returns stdout and stderr logs of the container
+func (client *Client) ContainerLogs(containerID string) (Logs, error) {
+	query := url.Values{}
+	query.Set("stdout", "1")
+	query.Set("stderr", "1")
+
+	resp, err := client.do(http.MethodGet, "/containers/"+containerID+"/logs", query, nil, http.StatusOK)
+	if err != nil {
+		return Logs{}, err
+	}
+	defer resp.Body.Close()
+
+	var logs Logs
+	logs.Stdout, logs.Stderr, err = demultiplexStream(resp.Body)
+	return logs, err
+}
+
+// ContainerInspect returns the detailed information of the container
+func (client *Client) ContainerInspect(containerID string) (Container, error) {
+	var container Container
+	err := client.doJSON(http.MethodGet, "/containers/"+containerID+"/json", nil, nil, &container, http.StatusOK)
+	return container, err
+}
+
+// ContainerStop stops the container. Docker kills the container if it does not stop within timeout.
+// Stopping an already stopped container is not an error.
+func (client *Client) ContainerStop(containerID string, timeout time.Duration) error {
+	query := url.Values{}
+	query.Set("t", strconv.Itoa(int(timeout.Seconds())))
+	return client.doJSON(http.MethodPost, "/containers/"+containerID+"/stop", query, nil, nil, http.StatusNoContent, http.StatusNotModified)
+}
+
+// ContainerRemove removes the container, `force` kills it first if it is running
+func (client *Client) ContainerRemove(containerID string, force bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	return client.doJSON(http.MethodDelete, "/containers/"+containerID, query, nil, nil, http.StatusNoContent)
+}
+
+// ContainerListByLabel returns all the containers (running or not) which have the supplied label.
+// `label` is either a key or key=value.
+func (client *Client) ContainerListByLabel(label string) ([]ContainerSummary, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {label}})
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("all", "1")
+	query.Set("filters", string(filters))
+
+	var containers []ContainerSummary
+	err = client.doJSON(http.MethodGet, "/containers/json", query, nil, &containers, http.StatusOK)
+	return containers, err
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openebs/CITF/utils/log"
+)
+
+// Client talks to the Docker Engine REST API
+type Client struct {
+	// RunID is the value of the run label which is put on every container this Client runs.
+	// Blank means the run ID of package level citf configurations.
+	RunID string
+
+	// Logger is the logger of the requests of this Client, zero value means package level logger
+	Logger log.Logger
+
+	httpClient *http.Client
+	baseURL    string
+}
+
+// APIError is the error returned by docker daemon
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *APIError) Error() string {
+	return fmt.Sprintf("docker daemon responded with status %d: %s", err.StatusCode, err.Message)
+}
+
+// IsNotFound returns whether the supplied error is an APIError for a missing object
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// NewClient returns a Client which talks to the docker daemon listening on the supplied unix socket
+func NewClient(socketPath string) *Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	// host part of the URL is ignored as every connection is made to the socket
+	return newClientForURL("http://docker", &http.Client{Transport: transport})
+}
+
+// newClientForURL returns a Client which talks to the docker daemon on the supplied URL
+func newClientForURL(baseURL string, httpClient *http.Client) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// do sends the request to the daemon and returns the response if its status is one of `okStatuses`
+// `body` is marshalled to JSON if it is not nil. Caller should close the body of the response.
+func (client *Client) do(method, path string, query url.Values, body interface{}, okStatuses ...int) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request body. Error: %+v", err)
+		}
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+
+	reqURL := client.baseURL + path
+	if len(query) != 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, reqURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client.Logger.PrintfDebugMessage("docker API request: %s %s", method, reqURL)
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while requesting %s %s. Error: %+v", method, path, err)
+	}
+
+	for _, status := range okStatuses {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+
+	defer resp.Body.Close()
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	var message struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(respBody, &message) == nil && message.Message != "" {
+		apiErr.Message = message.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(respBody))
+	}
+	return nil, apiErr
+}
+
+// doJSON sends the request and decodes the JSON response into `out` if it is not nil
+func (client *Client) doJSON(method, path string, query url.Values, body, out interface{}, okStatuses ...int) error {
+	resp, err := client.do(method, path, query, body, okStatuses...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response of %s %s. Error: %+v", method, path, err)
+	}
+	return nil
+}
+
+// demultiplexStream splits the multiplexed stream of docker into stdout and stderr.
+// Each frame of the stream has 8 bytes header, first byte of which is the stream type
+// and last four bytes are the big endian size of the payload.
+func demultiplexStream(stream io.Reader) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(stream, header)
+		if err == io.EOF {
+			return stdout.String(), stderr.String(), nil
+		}
+		if err != nil {
+			return stdout.String(), stderr.String(), fmt.Errorf("error reading stream header. Error: %+v", err)
+		}
+
+		var dst io.Writer
+		switch header[0] {
+		case 0, 1:
+			dst = &stdout
+		case 2:
+			dst = &stderr
+		default:
+			return stdout.String(), stderr.String(), fmt.Errorf("unknown stream type %d", header[0])
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err = io.CopyN(dst, stream, size); err != nil {
+			return stdout.String(), stderr.String(), fmt.Errorf("error reading stream payload. Error: %+v", err)
+		}
+	}
+}
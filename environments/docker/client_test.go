@@ -0,0 +1,221 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+)
+
+// frame returns a frame of docker's multiplexed stream
+func frame(streamType byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = streamType
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+// newFakeDaemon returns a client connected to a fake docker daemon served by the supplied handler
+func newFakeDaemon(t *testing.T, handler http.Handler) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	return newClientForURL(server.URL, server.Client()), server.Close
+}
+
+func TestContainerRun(t *testing.T) {
+	var createdConfig ContainerConfig
+	started := false
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "my-container" {
+			t.Errorf("name in query = %q, want %q", r.URL.Query().Get("name"), "my-container")
+		}
+		json.NewDecoder(r.Body).Decode(&createdConfig)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc123"}`))
+	})
+	mux.HandleFunc("/containers/abc123/start", func(w http.ResponseWriter, r *http.Request) {
+		started = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+	defer closeDaemon()
+
+	id, err := client.ContainerRun(RunOptions{
+		Name:   "my-container",
+		Image:  "busybox",
+		Cmd:    []string{"sleep", "100"},
+		Labels: map[string]string{"app": "test"},
+	})
+	if err != nil {
+		t.Fatalf("ContainerRun() returned error: %+v", err)
+	}
+	if id != "abc123" {
+		t.Errorf("ContainerRun() = %q, want %q", id, "abc123")
+	}
+	if !started {
+		t.Errorf("ContainerRun() did not start the container")
+	}
+	if createdConfig.Image != "busybox" || createdConfig.Labels["app"] != "test" {
+		t.Errorf("container created with unexpected config: %+v", createdConfig)
+	}
+	if createdConfig.Labels[common.RunIDLabel] != config.RunID() {
+		t.Errorf("container label %q = %q, want %q", common.RunIDLabel, createdConfig.Labels[common.RunIDLabel], config.RunID())
+	}
+}
+
+func TestContainerExec(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc123/exec", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "exec1"}`))
+	})
+	mux.HandleFunc("/exec/exec1/start", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(frame(1, "hello "))
+		w.Write(frame(2, "oops"))
+		w.Write(frame(1, "world"))
+	})
+	mux.HandleFunc("/exec/exec1/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ExitCode": 3}`))
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+	defer closeDaemon()
+
+	result, err := client.ContainerExec("abc123", []string{"echo", "hello"})
+	if err != nil {
+		t.Fatalf("ContainerExec() returned error: %+v", err)
+	}
+	want := ExecResult{ExitCode: 3, Stdout: "hello world", Stderr: "oops"}
+	if result != want {
+		t.Errorf("ContainerExec() = %+v, want %+v", result, want)
+	}
+}
+
+func TestContainerInspectAndLogs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc123/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "abc123", "Name": "/my-container", "Config": {"Image": "busybox"}, "State": {"Status": "running", "Running": true}}`))
+	})
+	mux.HandleFunc("/containers/abc123/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(frame(1, "out"))
+		w.Write(frame(2, "err"))
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+	defer closeDaemon()
+
+	container, err := client.ContainerInspect("abc123")
+	if err != nil {
+		t.Fatalf("ContainerInspect() returned error: %+v", err)
+	}
+	if container.Name != "/my-container" || container.Config.Image != "busybox" || !container.State.Running {
+		t.Errorf("ContainerInspect() = %+v", container)
+	}
+
+	logs, err := client.ContainerLogs("abc123")
+	if err != nil {
+		t.Fatalf("ContainerLogs() returned error: %+v", err)
+	}
+	if logs.Stdout != "out" || logs.Stderr != "err" {
+		t.Errorf("ContainerLogs() = %+v", logs)
+	}
+
+	_, err = client.ContainerInspect("missing")
+	if !IsNotFound(err) {
+		t.Errorf("ContainerInspect() of missing container error = %v, want not found error", err)
+	}
+}
+
+func TestTeardownRemovesOnlyLabelledContainers(t *testing.T) {
+	var removed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		var filters map[string][]string
+		json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		want := common.RunIDLabel + "=" + config.RunID()
+		if len(filters["label"]) != 1 || filters["label"][0] != want {
+			t.Errorf("list filters = %v, want label %q", filters, want)
+		}
+		w.Write([]byte(`[{"Id": "c1"}, {"Id": "c2"}]`))
+	})
+	mux.HandleFunc("/containers/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			if r.URL.Query().Get("t") != "10" {
+				t.Errorf("stop timeout = %q, want %q", r.URL.Query().Get("t"), "10")
+			}
+			w.WriteHeader(http.StatusNotModified)
+		case http.MethodDelete:
+			removed = append(removed, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+	defer closeDaemon()
+
+	err := Docker{Client: client}.Teardown()
+	if err != nil {
+		t.Fatalf("Teardown() returned error: %+v", err)
+	}
+	if len(removed) != 2 || removed[0] != "/containers/c1" || removed[1] != "/containers/c2" {
+		t.Errorf("Teardown() removed %q", removed)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version": "18.06.1-ce", "ApiVersion": "1.38"}`))
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+
+	status, err := Docker{Client: client}.Status()
+	if err != nil {
+		t.Fatalf("Status() returned error: %+v", err)
+	}
+	if status["daemon"] != "Running" || status["version"] != "18.06.1-ce" || status["apiVersion"] != "1.38" {
+		t.Errorf("Status() = %v", status)
+	}
+
+	closeDaemon()
+	status, err = Docker{Client: client}.Status()
+	if err == nil || status["daemon"] != "Unreachable" {
+		t.Errorf("Status() of stopped daemon = %v, %v; want Unreachable and error", status, err)
+	}
+}
+
+func TestContainerStopError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc123/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message": "cannot stop container"}`))
+	})
+	client, closeDaemon := newFakeDaemon(t, mux)
+	defer closeDaemon()
+
+	err := client.ContainerStop("abc123", time.Second)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusInternalServerError || apiErr.Message != "cannot stop container" {
+		t.Errorf("ContainerStop() error = %#v", err)
+	}
+}
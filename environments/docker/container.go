@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+)
+
+// runLabelKey returns the key of the label which is put on every container started by CITF
+func runLabelKey() string {
+	return common.RunIDLabel
+}
+
+// runLabelValue returns the value of the label which is put on every container started by this client
+func (client *Client) runLabelValue() string {
+	if client.RunID != "" {
+		return client.RunID
+	}
+	return config.RunID()
+}
+
+// RunContainer starts a container according to the options supplied and returns its ID.
+// Container is labelled with current run ID so that Teardown removes it.
+func (docker Docker) RunContainer(options RunOptions) (string, error) {
+	return docker.client().ContainerRun(options)
+}
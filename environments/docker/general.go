@@ -14,47 +14,84 @@ limitations under the License.
 package docker
 
 import (
-	"os"
-	"strings"
-
 	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/environments"
 	"github.com/openebs/CITF/utils/log"
-	sysutil "github.com/openebs/CITF/utils/system"
 )
 
 var logger log.Logger
 
-var (
-	useSudo     = true // Default value to use sudo
-	execCommand = sysutil.ExecCommandWithSudo
-	runCommand  = sysutil.RunCommandWithSudo
-)
-
 func init() {
-	// `sudo` use detection
-	useSudoEnv := strings.ToLower(strings.TrimSpace(os.Getenv("USE_SUDO")))
-	if useSudoEnv == "true" { // If it is mentioned in the environment variable to use sudo
-		useSudo = true // use sudo then
-	} else if useSudoEnv == "false" { // Else if it is mentioned in the environment variable not to use sudo
-		useSudo = false // do not use sudo
-	} // Else use default value mentioned above
-
-	if !useSudo {
-		execCommand = sysutil.ExecCommand
-		runCommand = sysutil.RunCommand
-	}
+	environments.Register(common.Docker, func(conf *config.Config) (environments.Environment, error) {
+		return NewDockerForConfig(conf), nil
+	})
 }
 
 // Docker is a struct which will be the driver for all the methods related to docker
 // Docker implements github.com/openebs/CITF/Environment interface
-type Docker struct{}
+type Docker struct {
+	// Client talks to the docker daemon. If it is nil, a client for the
+	// socket mentioned in citf configurations is used.
+	Client *Client
+
+	// Config is the citf configuration of this Docker, nil means package level configurations
+	Config *config.Config
+}
+
+var _ environments.Environment = Docker{}
+var _ environments.Configurable = Docker{}
 
-// NewDocker returns Docker struct
+// NewDocker returns Docker struct which talks to the docker daemon on the socket
+// mentioned in citf configurations
 func NewDocker() Docker {
-	return Docker{}
+	return NewDockerForConfig(config.Global())
+}
+
+// NewDockerForConfig returns Docker struct which talks to the docker daemon on the socket
+// mentioned in supplied citf configurations
+func NewDockerForConfig(conf *config.Config) Docker {
+	docker := Docker{Config: conf}
+	docker.Client = docker.client()
+	return docker
 }
 
 // Name returns the name of the environment, In this case common.Docker
 func (docker Docker) Name() string {
 	return common.Docker
 }
+
+// conf returns the citf configuration of this Docker
+func (docker Docker) conf() *config.Config {
+	if docker.Config != nil {
+		return docker.Config
+	}
+	return config.Global()
+}
+
+// logger returns the logger of the citf configuration of this Docker
+func (docker Docker) logger() log.Logger {
+	return docker.conf().Logger()
+}
+
+// WithConfig returns a copy of this Docker which uses conf, it implements environments.Configurable
+func (docker Docker) WithConfig(conf *config.Config) environments.Environment {
+	docker.Config = conf
+	if docker.Client != nil {
+		client := *docker.Client
+		client.Logger = conf.Logger()
+		docker.Client = &client
+	}
+	return docker
+}
+
+// client returns the client of docker, creating one from citf configurations if not set
+func (docker Docker) client() *Client {
+	if docker.Client != nil {
+		return docker.Client
+	}
+	client := NewClient(docker.conf().DockerSocketPath())
+	client.RunID = docker.conf().RunID()
+	client.Logger = docker.logger()
+	return client
+}
@@ -15,23 +15,46 @@ package docker
 
 import (
 	"fmt"
-	"strings"
+	"time"
+
+	"github.com/openebs/CITF/environments"
 )
 
-// Teardown stops all the docker containers present on the machine
+// stopTimeout is the time docker waits for a container to stop before killing it
+const stopTimeout = 10 * time.Second
+
+// Teardown stops and removes only the docker containers which were started by this run of CITF
+// i.e. which are labelled with current run ID. Other containers on the machine are not touched.
+// Containers are kept if the teardown policy of the configuration says so (see environments.TeardownIfAllowed).
 func (docker Docker) Teardown() error {
-	// CAUTION: This function call stops all docker containers
-	containersStr, err := execCommand("docker ps -q")
+	return environments.TeardownIfAllowed(docker, docker.conf(), docker.teardown)
+}
+
+// teardown stops and removes the docker containers started by this run of CITF, regardless of teardown policy
+func (docker Docker) teardown() error {
+	client := docker.client()
+	containers, err := client.ContainerListByLabel(runLabelKey() + "=" + client.runLabelValue())
 	if err != nil {
-		return fmt.Errorf("error while getting container id. Error: %+v", err)
+		return fmt.Errorf("error while listing containers. Error: %+v", err)
 	}
-	if containersStr != "" {
-		containers := strings.Fields(containersStr)
-		for _, container := range containers {
-			err = runCommand("docker stop -f " + container)
-			logger.LogErrorf(err, "error occurred while stopping docker container: %s", container)
-			logger.PrintNonErrorf(err, "Stopped container: %s", container)
+
+	var failed []string
+	for _, container := range containers {
+		err = client.ContainerStop(container.ID, stopTimeout)
+		docker.logger().LogErrorf(err, "error occurred while stopping docker container: %s", container.ID)
+		if err == nil {
+			err = client.ContainerRemove(container.ID, false)
+			docker.logger().LogErrorf(err, "error occurred while removing docker container: %s", container.ID)
+		}
+		if err != nil {
+			failed = append(failed, container.ID)
+			continue
 		}
+		docker.logger().PrintNonErrorf(err, "Removed container: %s", container.ID)
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to stop or remove containers: %q", failed)
 	}
 	return nil
 }
@@ -13,8 +13,25 @@ limitations under the License.
 
 package docker
 
-// CheckStatus should return the status of docker environment.
-// It returns nil,nil for now. It has been written just to implement interface Environment
-func (docker Docker) CheckStatus() (map[string]string, error) {
-	return nil, nil
+import "fmt"
+
+// Status returns the health of the docker daemon and its version.
+// Keys of the returned map are "daemon", "version" and "apiVersion".
+// It returns error if daemon is not healthy.
+func (docker Docker) Status() (map[string]string, error) {
+	status := map[string]string{}
+
+	if err := docker.client().Ping(); err != nil {
+		status["daemon"] = "Unreachable"
+		return status, fmt.Errorf("docker daemon is not reachable. Error: %+v", err)
+	}
+	status["daemon"] = "Running"
+
+	version, err := docker.client().Version()
+	if err != nil {
+		return status, fmt.Errorf("error getting docker version. Error: %+v", err)
+	}
+	status["version"] = version.Version
+	status["apiVersion"] = version.APIVersion
+	return status, nil
 }
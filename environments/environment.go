@@ -13,6 +13,8 @@ limitations under the License.
 
 package environments
 
+import "github.com/openebs/CITF/config"
+
 // Environment is the interface which integrate all the functionalities
 // that environments like minikube, docker etc should have.
 type Environment interface {
@@ -21,3 +23,17 @@ type Environment interface {
 	Status() (map[string]string, error)
 	Teardown() error
 }
+
+// KubeConfigProvider is implemented by the environments which write a kube-config of their own
+// for the run, so that user's kube-config is never touched.
+type KubeConfigProvider interface {
+	// KubeConfigPath returns the path of the kube-config written by the environment
+	KubeConfigPath() string
+}
+
+// Configurable is implemented by the environments which read their configurations from a config.Config,
+// so that a copy of them can use another Config e.g. one which logs to the output of a test.
+type Configurable interface {
+	// WithConfig returns a copy of the environment which uses conf
+	WithConfig(conf *config.Config) Environment
+}
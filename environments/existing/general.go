@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package existing
+
+import (
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/environments"
+	"github.com/openebs/CITF/utils/log"
+)
+
+var logger log.Logger
+
+func init() {
+	environments.Register(common.Existing, func(conf *config.Config) (environments.Environment, error) {
+		return NewExistingForConfig(conf), nil
+	})
+}
+
+// Existing is a struct which will be the driver for all the methods related to an already running cluster.
+// It never provisions or deletes the cluster, it only verifies that cluster is usable.
+// Existing implements github.com/openebs/CITF/Environment interface
+type Existing struct {
+	// RequiredNamespaces are the namespaces which must be present in the cluster
+	RequiredNamespaces []string
+
+	// RequiredCRDs are the names of the CustomResourceDefinitions (e.g. "disks.openebs.io")
+	// which must be present in the cluster
+	RequiredCRDs []string
+
+	// Config is the citf configuration of this Existing, which tells how to connect to the cluster.
+	// nil means package level configurations.
+	Config *config.Config
+}
+
+// NewExisting returns an Existing struct filled according to citf configurations
+func NewExisting() Existing {
+	return NewExistingForConfig(config.Global())
+}
+
+// NewExistingForConfig returns an Existing struct filled according to supplied citf configurations
+func NewExistingForConfig(conf *config.Config) Existing {
+	return Existing{
+		RequiredNamespaces: conf.ExistingRequiredNamespaces(),
+		RequiredCRDs:       conf.ExistingRequiredCRDs(),
+		Config:             conf,
+	}
+}
+
+// conf returns the citf configuration of this Existing
+func (existing Existing) conf() *config.Config {
+	if existing.Config != nil {
+		return existing.Config
+	}
+	return config.Global()
+}
+
+// logger returns the logger of the citf configuration of this Existing
+func (existing Existing) logger() log.Logger {
+	return existing.conf().Logger()
+}
+
+// WithConfig returns a copy of this Existing which uses conf, it implements environments.Configurable
+func (existing Existing) WithConfig(conf *config.Config) environments.Environment {
+	existing.Config = conf
+	return existing
+}
+
+// Name returns the name of the environment, In this case common.Existing
+func (existing Existing) Name() string {
+	return common.Existing
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package existing
+
+// Setup does not provision anything, it is a preflight check which verifies that
+// api server is reachable, all nodes are Ready and required namespaces and CRDs are present.
+func (existing Existing) Setup() error {
+	status, err := existing.Status()
+	existing.logger().PrintfDebugMessage("existing cluster status: %q", status)
+	if err != nil {
+		return err
+	}
+
+	existing.logger().Info("existing cluster is ready to use")
+	return nil
+}
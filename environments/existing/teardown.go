@@ -0,0 +1,44 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package existing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openebs/CITF/environments"
+	"github.com/openebs/CITF/utils/k8s"
+)
+
+// teardownTimeout is the time to wait for the objects created by this run to be gone
+const teardownTimeout = 5 * time.Minute
+
+// Teardown never deletes the cluster. It deletes only the objects which were created by this run of CITF
+// i.e. which are labelled with current run ID, in reverse dependency order (see K8S.DeleteRunObjects).
+// Objects are kept if the teardown policy of the configuration says so (see environments.TeardownIfAllowed).
+func (existing Existing) Teardown() error {
+	return environments.TeardownIfAllowed(existing, existing.conf(), existing.teardown)
+}
+
+// teardown deletes the objects created by this run of CITF, regardless of teardown policy
+func (existing Existing) teardown() error {
+	k8sInstance, err := k8s.NewK8SForConfig(existing.conf())
+	if err != nil {
+		return fmt.Errorf("error creating K8S for the cluster. Error: %+v", err)
+	}
+
+	deleted, err := k8sInstance.DeleteRunObjects(teardownTimeout)
+	existing.logger().PrintfDebugMessage("deleted objects created by this run: %q", deleted)
+	return err
+}
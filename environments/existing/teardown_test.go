@@ -0,0 +1,199 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package existing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// fakeAPIServer serves list, get and delete of the objects it has, like an api server which serves
+// only Namespaces, PersistentVolumeClaims and StorageClasses i.e. has no OpenEBS CRDs installed
+type fakeAPIServer struct {
+	mutex sync.Mutex
+	// objects are keyed by resource, then by "<namespace>/<name>"
+	objects map[string]map[string]map[string]interface{}
+	// deleted are the "<resource>/<namespace>/<name>" of the objects deleted, in order
+	deleted []string
+}
+
+// listKinds are the kinds of the lists of the resources served, keyed by resource
+var listKinds = map[string][2]string{
+	"namespaces":             {"v1", "NamespaceList"},
+	"persistentvolumeclaims": {"v1", "PersistentVolumeClaimList"},
+	"storageclasses":         {"storage.k8s.io/v1", "StorageClassList"},
+}
+
+func (server *fakeAPIServer) add(resource, apiVersion, kind, namespace, name, runID string) {
+	metadata := map[string]interface{}{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	if runID != "" {
+		metadata["labels"] = map[string]interface{}{common.RunIDLabel: runID}
+	}
+	if server.objects[resource] == nil {
+		server.objects[resource] = map[string]map[string]interface{}{}
+	}
+	server.objects[resource][namespace+"/"+name] = map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "metadata": metadata}
+}
+
+func (server *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	// /api/v1/... or /apis/<group>/<version>/...
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if segments[0] == "api" {
+		segments = segments[2:]
+	} else {
+		segments = segments[3:]
+	}
+	namespace := ""
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		namespace, segments = segments[1], segments[2:]
+	}
+	resource := segments[0]
+	listKind, served := listKinds[resource]
+	if !served {
+		writeStatus(w, http.StatusNotFound, "the server could not find the requested resource")
+		return
+	}
+
+	if len(segments) == 1 {
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			writeStatus(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		items := []interface{}{}
+		for _, object := range server.objects[resource] {
+			objectLabels := map[string]string{}
+			metadataLabels, _ := object["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			for key, value := range metadataLabels {
+				objectLabels[key] = value.(string)
+			}
+			if selector.Matches(labels.Set(objectLabels)) {
+				items = append(items, object)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"apiVersion": listKind[0], "kind": listKind[1], "items": items})
+		return
+	}
+
+	key := namespace + "/" + segments[1]
+	object, present := server.objects[resource][key]
+	if !present {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("%s %q not found", resource, key))
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(server.objects[resource], key)
+		server.deleted = append(server.deleted, resource+"/"+key)
+	}
+	writeJSON(w, http.StatusOK, object)
+}
+
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	reason := "BadRequest"
+	if code == http.StatusNotFound {
+		reason = "NotFound"
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"apiVersion": "v1", "kind": "Status", "status": "Failure", "message": message, "reason": reason, "code": code,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// newTestExisting returns Existing whose configuration has runID and a kube-config of the cluster at serverURL
+func newTestExisting(t *testing.T, serverURL, runID string) Existing {
+	dir, err := ioutil.TempDir("", "citf-existing")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %+v", err)
+	}
+
+	kubeConfigPath := filepath.Join(dir, "kubeconfig")
+	kubeConfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+current-context: fake
+`, serverURL)
+	confPath := filepath.Join(dir, "config.yaml")
+	conf := fmt.Sprintf("runID: %s\nkubeConfigPath: %s\n", runID, kubeConfigPath)
+	if err = ioutil.WriteFile(kubeConfigPath, []byte(kubeConfig), 0600); err != nil {
+		t.Fatalf("unable to write kube-config: %+v", err)
+	}
+	if err = ioutil.WriteFile(confPath, []byte(conf), 0600); err != nil {
+		t.Fatalf("unable to write configuration: %+v", err)
+	}
+
+	citfConfig, err := config.NewConfig(confPath)
+	if err != nil {
+		t.Fatalf("unable to load configuration: %+v", err)
+	}
+	return Existing{Config: citfConfig}
+}
+
+func TestTeardown(t *testing.T) {
+	server := &fakeAPIServer{objects: map[string]map[string]map[string]interface{}{}}
+	server.add("namespaces", "v1", "Namespace", "", "citf-test-abcde", "test-run")
+	server.add("namespaces", "v1", "Namespace", "", "apps", "older-run")
+	server.add("persistentvolumeclaims", "v1", "PersistentVolumeClaim", "citf-test-abcde", "data", "test-run")
+	server.add("storageclasses", "storage.k8s.io/v1", "StorageClass", "", "citf-sc", "test-run")
+	server.add("storageclasses", "storage.k8s.io/v1", "StorageClass", "", "standard", "")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	existing := newTestExisting(t, httpServer.URL, "test-run")
+	defer os.RemoveAll(filepath.Dir(existing.Config.KubeConfigPath()))
+
+	if err := existing.Teardown(); err != nil {
+		t.Fatalf("Teardown() returned error: %+v", err)
+	}
+
+	// objects of the run are deleted in reverse dependency order, those of other runs or not of CITF are kept
+	expected := []string{
+		"persistentvolumeclaims/citf-test-abcde/data",
+		"namespaces//citf-test-abcde",
+		"storageclasses//citf-sc",
+	}
+	if !reflect.DeepEqual(server.deleted, expected) {
+		t.Errorf("Teardown() deleted %q, expected %q", server.deleted, expected)
+	}
+}
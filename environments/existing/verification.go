@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package existing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openebs/CITF/utils/k8s"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	statusPresent = "Present"
+	statusAbsent  = "Absent"
+)
+
+// getClientset returns clientset for the cluster using citf configurations of this Existing
+func (existing Existing) getClientset() (*kubernetes.Clientset, error) {
+	clientConfig, err := k8s.GetClientConfigForConfig(existing.conf())
+	if err != nil {
+		return nil, err
+	}
+	return k8s.GetClientsetFromConfig(clientConfig)
+}
+
+// getDynamicClient returns dynamic client for the cluster using citf configurations of this Existing
+func (existing Existing) getDynamicClient() (dynamic.Interface, error) {
+	clientConfig, err := k8s.GetClientConfigForConfig(existing.conf())
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(clientConfig)
+}
+
+// crdResource is the resource of CustomResourceDefinitions
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions"}
+
+// presentCRDs returns the set of the names of the CustomResourceDefinitions present in the cluster
+// i.e. <plural>.<group> e.g. "disks.openebs.io"
+func presentCRDs(client dynamic.Interface) (map[string]bool, error) {
+	list, err := client.Resource(crdResource).List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	crds := map[string]bool{}
+	for _, crd := range list.Items {
+		crds[crd.GetName()] = true
+	}
+	return crds, nil
+}
+
+// checkCluster checks every requirement of the cluster and fills the status accordingly.
+// It returns the status and the list of the problems found.
+func (existing Existing) checkCluster() (map[string]string, []string) {
+	status := map[string]string{}
+	var problems []string
+
+	clientset, err := existing.getClientset()
+	if err != nil {
+		status["apiserver"] = "Unreachable"
+		return status, []string{fmt.Sprintf("unable to create client for the cluster: %+v", err)}
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		status["apiserver"] = "Unreachable"
+		return status, []string{fmt.Sprintf("api server is not reachable: %+v", err)}
+	}
+	status["apiserver"] = "Running"
+	status["version"] = version.GitVersion
+
+	nodes, err := clientset.CoreV1().Nodes().List(meta_v1.ListOptions{})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("unable to list nodes: %+v", err))
+	} else if len(nodes.Items) == 0 {
+		problems = append(problems, "cluster has no nodes")
+	} else {
+		for _, node := range nodes.Items {
+			if (k8s.K8S{}).IsNodeReady(node) {
+				status["node/"+node.Name] = "Ready"
+			} else {
+				status["node/"+node.Name] = "NotReady"
+				problems = append(problems, fmt.Sprintf("node %q is not ready", node.Name))
+			}
+		}
+	}
+
+	if len(existing.RequiredNamespaces) != 0 {
+		k8sInstance := k8s.K8S{Clientset: clientset}
+		namespaces, err := k8sInstance.GetAllNamespacesMap()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("unable to list namespaces: %+v", err))
+		} else {
+			for _, name := range existing.RequiredNamespaces {
+				namespace, ok := namespaces[name]
+				if !ok {
+					status["namespace/"+name] = statusAbsent
+					problems = append(problems, fmt.Sprintf("namespace %q is not present", name))
+					continue
+				}
+				status["namespace/"+name] = string(namespace.Status.Phase)
+				if !k8sInstance.IsNSinGoodPhase(namespace) {
+					problems = append(problems, fmt.Sprintf("namespace %q is in phase %q", name, namespace.Status.Phase))
+				}
+			}
+		}
+	}
+
+	if len(existing.RequiredCRDs) != 0 {
+		var crds map[string]bool
+		dynamicClient, err := existing.getDynamicClient()
+		if err == nil {
+			crds, err = presentCRDs(dynamicClient)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("unable to list custom resource definitions: %+v", err))
+		} else {
+			for _, name := range existing.RequiredCRDs {
+				if crds[name] {
+					status["crd/"+name] = statusPresent
+				} else {
+					status["crd/"+name] = statusAbsent
+					problems = append(problems, fmt.Sprintf("custom resource definition %q is not present", name))
+				}
+			}
+		}
+	}
+
+	return status, problems
+}
+
+// Status returns the detailed status of the cluster. Keys of the returned map are
+// "apiserver", "version", "node/<name>", "namespace/<name>" and "crd/<name>".
+// It returns error describing all the problems found, if any.
+func (existing Existing) Status() (map[string]string, error) {
+	status, problems := existing.checkCluster()
+	if len(problems) != 0 {
+		return status, fmt.Errorf("existing cluster is not usable: %s", strings.Join(problems, "; "))
+	}
+	return status, nil
+}
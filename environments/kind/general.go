@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/environments"
+	"github.com/openebs/CITF/utils/log"
+	sysutil "github.com/openebs/CITF/utils/system"
+)
+
+var logger log.Logger
+
+var (
+	execCommand = sysutil.ExecCommand
+	runCommand  = sysutil.RunCommand
+)
+
+func init() {
+	// Check if `kind` is present
+	kindPath, err := sysutil.BinPathFromPathEnv(common.Kind)
+	if kindPath == "" {
+		// we don't want to exit here because kind may not be the environment in use
+		logger.PrintlnDebugMessage(common.Kind, "not found in current directory or in directories represented by PATH environment variable:", err)
+	}
+
+	environments.Register(common.Kind, func(conf *config.Config) (environments.Environment, error) {
+		return NewKindForConfig(conf), nil
+	})
+}
+
+// Kind is a struct which will be the driver for all the methods related to kind (Kubernetes IN Docker)
+// Kind implements github.com/openebs/CITF/Environment interface
+type Kind struct {
+	// ClusterName is the name of the kind cluster, CITF creates and deletes only this cluster
+	ClusterName string
+
+	// Nodes is the total number of nodes in cluster. First one is the control-plane
+	// and rest of them are workers
+	Nodes int
+
+	// NodeImage is the node image kind should use. Blank means kind's default
+	NodeImage string
+
+	// Timeout is the timeout that will be used throughout the kind package
+	// for timeout in any operation if requires.
+	Timeout time.Duration
+
+	// Config is the citf configuration of this Kind, kube-config of the cluster is set in it.
+	// nil means package level configurations.
+	Config *config.Config
+}
+
+// NewKind returns a Kind struct filled according to citf configurations
+func NewKind() Kind {
+	return NewKindForConfig(config.Global())
+}
+
+// NewKindForConfig returns a Kind struct filled according to supplied citf configurations
+func NewKindForConfig(conf *config.Config) Kind {
+	return Kind{
+		ClusterName: conf.KindClusterName(),
+		Nodes:       conf.KindNodes(),
+		NodeImage:   conf.KindNodeImage(),
+		Timeout:     conf.KindTimeout(),
+		Config:      conf,
+	}
+}
+
+// conf returns the citf configuration of this Kind
+func (kind Kind) conf() *config.Config {
+	if kind.Config != nil {
+		return kind.Config
+	}
+	return config.Global()
+}
+
+// logger returns the logger of the citf configuration of this Kind
+func (kind Kind) logger() log.Logger {
+	return kind.conf().Logger()
+}
+
+// WithConfig returns a copy of this Kind which uses conf, it implements environments.Configurable
+func (kind Kind) WithConfig(conf *config.Config) environments.Environment {
+	kind.Config = conf
+	return kind
+}
+
+// Name returns the name of the environment, In this case common.Kind
+func (kind Kind) Name() string {
+	return common.Kind
+}
+
+// workDir returns the directory where files related to this cluster are kept for the current run
+func (kind Kind) workDir() string {
+	return filepath.Join(kind.conf().RunDir(), common.Kind+"-"+kind.ClusterName)
+}
+
+// KubeConfigPath returns the path of the kube-config which is written for this cluster in the current run only.
+// User's own kube-config is never touched by kind environment.
+func (kind Kind) KubeConfigPath() string {
+	return filepath.Join(kind.workDir(), "kubeconfig")
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openebs/CITF/common"
+)
+
+// kindConfigAPIVersion is the apiVersion of the kind cluster configuration
+const kindConfigAPIVersion = "kind.x-k8s.io/v1alpha4"
+
+// generateClusterConfig returns kind cluster configuration which has one control-plane
+// and (nodes - 1) workers
+func generateClusterConfig(nodes int) (string, error) {
+	if nodes < 1 {
+		return "", fmt.Errorf("kind cluster needs at least one node, got: %d", nodes)
+	}
+
+	var clusterConfig strings.Builder
+	clusterConfig.WriteString("kind: Cluster\n")
+	clusterConfig.WriteString("apiVersion: " + kindConfigAPIVersion + "\n")
+	clusterConfig.WriteString("nodes:\n")
+	clusterConfig.WriteString("- role: control-plane\n")
+	for i := 1; i < nodes; i++ {
+		clusterConfig.WriteString("- role: worker\n")
+	}
+	return clusterConfig.String(), nil
+}
+
+// clusterExists returns whether the kind cluster with the name of this cluster exists
+func (kind Kind) clusterExists() (bool, error) {
+	output, err := execCommand(common.Kind + " get clusters")
+	if err != nil {
+		return false, fmt.Errorf("error while getting kind clusters. Error: %+v", err)
+	}
+	for _, cluster := range strings.Fields(output) {
+		if cluster == kind.ClusterName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateCluster writes kind configuration of this cluster and creates the cluster with that.
+// kube-config of the cluster is written at `KubeConfigPath()`
+func (kind Kind) CreateCluster() error {
+	clusterConfig, err := generateClusterConfig(kind.Nodes)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(kind.workDir(), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating directory %q. Error: %+v", kind.workDir(), err)
+	}
+
+	clusterConfigPath := filepath.Join(kind.workDir(), "kind-config.yaml")
+	err = ioutil.WriteFile(clusterConfigPath, []byte(clusterConfig), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing kind config %q. Error: %+v", clusterConfigPath, err)
+	}
+	kind.logger().PrintfDebugMessage("kind config for cluster %q:\n%s", kind.ClusterName, clusterConfig)
+
+	command := common.Kind + " create cluster --name " + kind.ClusterName +
+		" --config " + clusterConfigPath +
+		" --kubeconfig " + kind.KubeConfigPath() +
+		" --wait " + kind.Timeout.String()
+	if kind.NodeImage != "" {
+		command += " --image " + kind.NodeImage
+	}
+
+	err = runCommand(command)
+	if err != nil {
+		return fmt.Errorf("error occurred while creating kind cluster %q. Error: %+v", kind.ClusterName, err)
+	}
+	return nil
+}
+
+// exportKubeConfig writes the kube-config of already existing cluster at `KubeConfigPath()`
+func (kind Kind) exportKubeConfig() error {
+	err := os.MkdirAll(kind.workDir(), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating directory %q. Error: %+v", kind.workDir(), err)
+	}
+
+	err = runCommand(common.Kind + " export kubeconfig --name " + kind.ClusterName + " --kubeconfig " + kind.KubeConfigPath())
+	if err != nil {
+		return fmt.Errorf("error occurred while exporting kube-config of kind cluster %q. Error: %+v", kind.ClusterName, err)
+	}
+	return nil
+}
+
+// Setup creates the kind cluster if it is not already there, otherwise it reuses the same.
+// In both cases kube-config of the cluster is written to a separate file
+// which is then used by CITF to connect to the cluster.
+func (kind Kind) Setup() error {
+	exists, err := kind.clusterExists()
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		kind.logger().Info("kind cluster is already present", "cluster", kind.ClusterName)
+		err = kind.exportKubeConfig()
+	} else {
+		kind.logger().Info("creating kind cluster", "cluster", kind.ClusterName, "nodes", kind.Nodes)
+		err = kind.CreateCluster()
+	}
+	if err != nil {
+		return err
+	}
+
+	kind.conf().SetEnvironmentKubeConfigPath(kind.KubeConfigPath())
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import "testing"
+
+func TestGenerateClusterConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "single node cluster",
+			nodes: 1,
+			want: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+`,
+		},
+		{
+			name:  "three nodes cluster",
+			nodes: 3,
+			want: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+- role: worker
+`,
+		},
+		{
+			name:    "no node",
+			nodes:   0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateClusterConfig(tt.nodes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generateClusterConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("generateClusterConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
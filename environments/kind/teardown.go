@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/environments"
+)
+
+// Teardown deletes only the kind cluster of this environment and its kube-config,
+// unless the teardown policy of the configuration keeps it (see environments.TeardownIfAllowed)
+func (kind Kind) Teardown() error {
+	return environments.TeardownIfAllowed(kind, kind.conf(), kind.teardown)
+}
+
+// teardown deletes the kind cluster of this environment and its kube-config, regardless of teardown policy
+func (kind Kind) teardown() error {
+	err := runCommand(common.Kind + " delete cluster --name " + kind.ClusterName + " --kubeconfig " + kind.KubeConfigPath())
+	if err != nil {
+		return fmt.Errorf("error occurred while deleting kind cluster %q. Error: %+v", kind.ClusterName, err)
+	}
+
+	err = os.RemoveAll(kind.workDir())
+	kind.logger().PrintErrorf(err, "error removing directory %q", kind.workDir())
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openebs/CITF/common"
+)
+
+// getNodes returns the names of the nodes (i.e. docker containers) of this cluster
+func (kind Kind) getNodes() ([]string, error) {
+	output, err := execCommand(common.Kind + " get nodes --name " + kind.ClusterName)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting nodes of kind cluster %q. Error: %+v", kind.ClusterName, err)
+	}
+	return strings.Fields(output), nil
+}
+
+// Status returns the state of every node of the cluster where key is the node name
+// and value is the state of the docker container of that node e.g. "running", "exited".
+// It returns error if the cluster has no nodes.
+func (kind Kind) Status() (map[string]string, error) {
+	nodes, err := kind.getNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	status := map[string]string{}
+	if len(nodes) == 0 {
+		return status, fmt.Errorf("no nodes found for kind cluster %q", kind.ClusterName)
+	}
+
+	for _, node := range nodes {
+		state, err := execCommand(common.Docker + " inspect --format {{.State.Status}} " + node)
+		if err != nil {
+			return status, fmt.Errorf("error while inspecting node %q of kind cluster %q. Error: %+v", node, kind.ClusterName, err)
+		}
+		status[node] = strings.TrimSpace(state)
+	}
+	return status, nil
+}
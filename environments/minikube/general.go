@@ -15,11 +15,13 @@ package minikube
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/environments"
 	"github.com/openebs/CITF/utils/log"
 	sysutil "github.com/openebs/CITF/utils/system"
 )
@@ -38,7 +40,7 @@ func init() {
 	if minikubePath == "" {
 		logger.LogFatalf(err, "%q not found in current directory or in directories represented by PATH environment variable", common.Minikube)
 	}
-	glog.Infof("%q found on path: %q", common.Minikube, minikubePath)
+	logger.Debug("found on path", "binary", common.Minikube, "path", minikubePath)
 
 	// `sudo` use detection
 	useSudoEnv := strings.ToLower(strings.TrimSpace(os.Getenv("USE_SUDO")))
@@ -52,6 +54,13 @@ func init() {
 		execCommand = sysutil.ExecCommand
 		runCommand = sysutil.RunCommand
 	}
+
+	environments.Register(common.Minikube, func(conf *config.Config) (environments.Environment, error) {
+		minikube := NewMinikube(MinikubeOptionsForConfig(conf))
+		minikube.Timeout = conf.MinikubeTimeout()
+		minikube.Config = conf
+		return minikube, nil
+	})
 }
 
 // Minikube is a struct which will be the driver for all the methods related to minikube
@@ -64,17 +73,66 @@ type Minikube struct {
 	// WaitTimeUnit is the time duration, which will be used throughout package
 	// if it needs to wait for some sub-task. (It is small timeout)
 	WaitTimeUnit time.Duration
+
+	// Options are the parameters with which minikube is started
+	Options MinikubeOptions
+
+	// ResumeExisting specifies whether Setup should resume a stopped or paused minikube.
+	// If it is false such a minikube is deleted and started again.
+	ResumeExisting bool
+
+	// Config is the citf configuration of this Minikube, kube-config of the cluster is set in it.
+	// nil means package level configurations.
+	Config *config.Config
 }
 
-// NewMinikube returns a Minikube struct
-func NewMinikube() Minikube {
+// NewMinikube returns a Minikube struct which starts minikube with the options supplied.
+// Options are validated when minikube is started.
+func NewMinikube(options MinikubeOptions) Minikube {
 	return Minikube{
-		Timeout:      time.Minute,
-		WaitTimeUnit: time.Second,
+		Timeout:        time.Minute,
+		WaitTimeUnit:   time.Second,
+		Options:        options,
+		ResumeExisting: true,
 	}
 }
 
+// conf returns the citf configuration of this Minikube
+func (minikube Minikube) conf() *config.Config {
+	if minikube.Config != nil {
+		return minikube.Config
+	}
+	return config.Global()
+}
+
+// logger returns the logger of the citf configuration of this Minikube
+func (minikube Minikube) logger() log.Logger {
+	return minikube.conf().Logger()
+}
+
+// WithConfig returns a copy of this Minikube which uses conf, it implements environments.Configurable
+func (minikube Minikube) WithConfig(conf *config.Config) environments.Environment {
+	minikube.Config = conf
+	return minikube
+}
+
 // Name returns the name of the environment, In this case common.Minikube
 func (minikube Minikube) Name() string {
 	return common.Minikube
 }
+
+// KubeConfigPath returns the path of the kube-config which is written for the current run only.
+// minikube writes its context there instead of user's own kube-config.
+func (minikube Minikube) KubeConfigPath() string {
+	name := common.Minikube
+	if minikube.Options.Profile != "" {
+		name += "-" + minikube.Options.Profile
+	}
+	return filepath.Join(minikube.conf().RunDir(), name+".kubeconfig")
+}
+
+// command returns the minikube command for the subcommand (along with its arguments) supplied.
+// KUBECONFIG is set through `env` so that it survives `sudo` as well.
+func (minikube Minikube) command(subcommand string) string {
+	return "env KUBECONFIG=" + minikube.KubeConfigPath() + " " + common.Minikube + " " + subcommand + minikube.Options.profileArgs()
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openebs/CITF/config"
+)
+
+var (
+	driverPattern            = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	memoryPattern            = regexp.MustCompile(`^[0-9]+([kKmMgG][bB]?)?$`)
+	kubernetesVersionPattern = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+([-+][0-9A-Za-z.-]+)?$`)
+	featureGatePattern       = regexp.MustCompile(`^[A-Za-z0-9]+=(true|false)$`)
+	extraConfigPattern       = regexp.MustCompile(`^[a-z-]+\.[A-Za-z0-9._-]+=[^\s]+$`)
+	profilePattern           = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// MinikubeOptions are the parameters with which minikube is started.
+// Blank (or zero) value of a field means minikube's default is used for that.
+type MinikubeOptions struct {
+	// Driver is the vm-driver e.g. "none", "virtualbox", "kvm2"
+	Driver string
+
+	// CPUs is the number of CPUs allocated to minikube
+	CPUs int
+
+	// Memory is the memory allocated to minikube e.g. "2048" (MB) or "2g"
+	Memory string
+
+	// KubernetesVersion is the version of kubernetes e.g. "v1.11.0"
+	KubernetesVersion string
+
+	// FeatureGates are the feature gates in the form Name=true|false e.g. "MountPropagation=true"
+	FeatureGates []string
+
+	// ExtraConfig are the configurations of kubernetes components in the form component.key=value
+	// e.g. "kubelet.max-pods=100"
+	ExtraConfig []string
+
+	// Profile is the name of minikube profile (i.e. name of the minikube cluster)
+	Profile string
+}
+
+// MinikubeOptionsFromConfig returns MinikubeOptions filled according to citf configurations
+func MinikubeOptionsFromConfig() MinikubeOptions {
+	return MinikubeOptionsForConfig(config.Global())
+}
+
+// MinikubeOptionsForConfig returns MinikubeOptions filled according to supplied citf configurations
+func MinikubeOptionsForConfig(conf *config.Config) MinikubeOptions {
+	return MinikubeOptions{
+		Driver:            conf.MinikubeDriver(),
+		CPUs:              conf.MinikubeCPUs(),
+		Memory:            conf.MinikubeMemory(),
+		KubernetesVersion: conf.MinikubeKubernetesVersion(),
+		FeatureGates:      conf.MinikubeFeatureGates(),
+		ExtraConfig:       conf.MinikubeExtraConfig(),
+		Profile:           conf.MinikubeProfile(),
+	}
+}
+
+// Validate returns error describing every invalid option, nil if all the options are valid
+func (options MinikubeOptions) Validate() error {
+	var problems []string
+
+	if !driverPattern.MatchString(options.Driver) {
+		problems = append(problems, fmt.Sprintf("invalid driver %q", options.Driver))
+	}
+	if options.CPUs < 0 {
+		problems = append(problems, fmt.Sprintf("invalid number of CPUs %d", options.CPUs))
+	}
+	if options.Memory != "" && !memoryPattern.MatchString(options.Memory) {
+		problems = append(problems, fmt.Sprintf("invalid memory %q, should be like \"2048\" or \"2g\"", options.Memory))
+	}
+	if options.KubernetesVersion != "" && !kubernetesVersionPattern.MatchString(options.KubernetesVersion) {
+		problems = append(problems, fmt.Sprintf("invalid kubernetes version %q, should be like \"v1.11.0\"", options.KubernetesVersion))
+	}
+	for _, featureGate := range options.FeatureGates {
+		if !featureGatePattern.MatchString(featureGate) {
+			problems = append(problems, fmt.Sprintf("invalid feature gate %q, should be like \"Name=true\"", featureGate))
+		}
+	}
+	for _, extraConfig := range options.ExtraConfig {
+		if !extraConfigPattern.MatchString(extraConfig) {
+			problems = append(problems, fmt.Sprintf("invalid extra config %q, should be like \"component.key=value\"", extraConfig))
+		}
+	}
+	if options.Profile != "" && !profilePattern.MatchString(options.Profile) {
+		problems = append(problems, fmt.Sprintf("invalid profile %q", options.Profile))
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid minikube options: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// profileArgs returns the arguments which select the profile for any minikube command
+func (options MinikubeOptions) profileArgs() string {
+	if options.Profile == "" {
+		return ""
+	}
+	return " --profile " + options.Profile
+}
+
+// startArgs validates the options and returns the arguments for `minikube start` except profile
+func (options MinikubeOptions) startArgs() (string, error) {
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
+
+	args := " --vm-driver=" + options.Driver
+	if options.CPUs != 0 {
+		args += " --cpus=" + strconv.Itoa(options.CPUs)
+	}
+	if options.Memory != "" {
+		args += " --memory=" + options.Memory
+	}
+	if options.KubernetesVersion != "" {
+		args += " --kubernetes-version=" + options.KubernetesVersion
+	}
+	if len(options.FeatureGates) != 0 {
+		args += " --feature-gates=" + strings.Join(options.FeatureGates, ",")
+	}
+	for _, extraConfig := range options.ExtraConfig {
+		args += " --extra-config=" + extraConfig
+	}
+	return args, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import "testing"
+
+func TestMinikubeOptionsStartArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		options MinikubeOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "only driver",
+			options: MinikubeOptions{Driver: "none"},
+			want:    " --vm-driver=none",
+		},
+		{
+			name: "all options",
+			options: MinikubeOptions{
+				Driver:            "virtualbox",
+				CPUs:              2,
+				Memory:            "2g",
+				KubernetesVersion: "v1.11.0",
+				FeatureGates:      []string{"MountPropagation=true", "CSIBlockVolume=false"},
+				ExtraConfig:       []string{"kubelet.max-pods=100", "apiserver.v=3"},
+				Profile:           "citf",
+			},
+			want: " --vm-driver=virtualbox --cpus=2 --memory=2g --kubernetes-version=v1.11.0" +
+				" --feature-gates=MountPropagation=true,CSIBlockVolume=false" +
+				" --extra-config=kubelet.max-pods=100 --extra-config=apiserver.v=3",
+		},
+		{
+			name:    "blank driver",
+			options: MinikubeOptions{},
+			wantErr: true,
+		},
+		{
+			name:    "negative CPUs",
+			options: MinikubeOptions{Driver: "none", CPUs: -1},
+			wantErr: true,
+		},
+		{
+			name:    "memory with space",
+			options: MinikubeOptions{Driver: "none", Memory: "2 g"},
+			wantErr: true,
+		},
+		{
+			name:    "kubernetes version without v",
+			options: MinikubeOptions{Driver: "none", KubernetesVersion: "1.11.0"},
+			wantErr: true,
+		},
+		{
+			name:    "feature gate without value",
+			options: MinikubeOptions{Driver: "none", FeatureGates: []string{"MountPropagation"}},
+			wantErr: true,
+		},
+		{
+			name:    "extra config without component",
+			options: MinikubeOptions{Driver: "none", ExtraConfig: []string{"max-pods=100"}},
+			wantErr: true,
+		},
+		{
+			name:    "profile with space",
+			options: MinikubeOptions{Driver: "none", Profile: "my profile"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.startArgs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("startArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("startArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
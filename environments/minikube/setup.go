@@ -16,106 +16,133 @@ package minikube
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/openebs/CITF/common"
 )
 
-// runPostStartCommandsForMinikube runs the commands required when run minikube as --vm-driver=none
+// runPostStartCommandsForMinikubeNoneDriver runs the commands required when run minikube as --vm-driver=none
+// with `sudo`, so that files created by minikube are accessible to the user.
+// User's own `.kube` is never touched, only kube-config of this run is handed over to the user.
+// `/root/.minikube` is moved to user's home only when user does not have a `.minikube` already.
 // Assumption: Environment variables `USER` and `HOME` is well defined.
 func (minikube Minikube) runPostStartCommandsForMinikubeNoneDriver() {
 	userName := os.Getenv("USER")
 	homeDir := os.Getenv("HOME")
 	commands := []string{
-		"mv /root/.kube " + homeDir + "/.kube",
-		"chown -R " + userName + " " + homeDir + "/.kube",
-		"chgrp -R " + userName + " " + homeDir + "/.kube",
-		"mv /root/.minikube " + homeDir + "/.minikube",
-		"chown -R " + userName + " " + homeDir + "/.minikube",
-		"chgrp -R " + userName + " " + homeDir + "/.minikube",
+		"chown " + userName + " " + minikube.KubeConfigPath(),
+		"chgrp " + userName + " " + minikube.KubeConfigPath(),
+	}
+
+	userDotMinikube := filepath.Join(homeDir, ".minikube")
+	if _, err := os.Stat(userDotMinikube); os.IsNotExist(err) {
+		commands = append(commands,
+			"mv /root/.minikube "+userDotMinikube,
+			"chown -R "+userName+" "+userDotMinikube,
+			"chgrp -R "+userName+" "+userDotMinikube,
+		)
 	}
 
 	for _, command := range commands {
-		fmt.Printf("Running %q\n", command)
+		minikube.logger().Info("running command", "command", command)
 		output, err := execCommand(command)
-		logger.PrintErrorf(err, "running %q failed", command)
-		logger.PrintNonErrorf(err, "run %q successfully. Output: %s", command, output)
+		minikube.logger().PrintErrorf(err, "running %q failed", command)
+		minikube.logger().PrintNonErrorf(err, "run %q successfully. Output: %s", command, output)
 	}
 }
 
-// StartMinikube method starts minikube with `--vm-driver=none` option.
+// StartMinikube method starts minikube with the options of this Minikube.
+// Options are validated before the command is built and error is returned if any option is invalid.
+// minikube writes its context to the kube-config of this run i.e. `KubeConfigPath()`.
 func (minikube Minikube) StartMinikube() error {
-	err := runCommand(common.Minikube + " start --vm-driver=none")
-	// We can also use following:
-	// "minikube start --vm-driver=none --feature-gates=MountPropagation=true --cpus=1 --memory=1024 --v=3 --alsologtostderr"
+	args, err := minikube.Options.startArgs()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(minikube.KubeConfigPath()), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating directory for kube-config. Error: %+v", err)
+	}
+
+	err = runCommand(minikube.command("start" + args))
 	if err != nil {
 		return fmt.Errorf("error occurred while starting minikube. Error: %+v", err)
 	}
 
+	// Below steps are required only for `none` driver where minikube runs on the host itself
+	if minikube.Options.Driver != "none" {
+		return nil
+	}
+
 	envChangeMinikubeNoneUser := os.Getenv("CHANGE_MINIKUBE_NONE_USER")
-	logger.PrintfDebugMessage("Environ CHANGE_MINIKUBE_NONE_USER = %q", envChangeMinikubeNoneUser)
+	minikube.logger().PrintfDebugMessage("Environ CHANGE_MINIKUBE_NONE_USER = %q", envChangeMinikubeNoneUser)
 
 	if envChangeMinikubeNoneUser == "true" {
 		// Below commands shall automatically run in this case.
-		logger.PrintlnDebugMessage("Returning from setup.")
+		minikube.logger().PrintlnDebugMessage("Returning from setup.")
 		return nil
 	}
 
-	minikube.waitForDotKubeDirToBeCreated()
+	if err = minikube.waitForKubeConfigToBeCreated(); err != nil {
+		return err
+	}
 
-	minikube.waitForDotMinikubeDirToBeCreated()
+	if err = minikube.waitForDotMinikubeDirToBeCreated(); err != nil {
+		return err
+	}
 
 	minikube.runPostStartCommandsForMinikubeNoneDriver()
 
 	return nil
 }
 
-// Setup checks if a teardown is required before minikube start
-// if so it does that and then start the minikube.
-// It does nothing when minikube is already running.
-// it prints status too.
+// writeKubeConfig writes the context of already running minikube to the kube-config of this run
+func (minikube Minikube) writeKubeConfig() error {
+	err := os.MkdirAll(filepath.Dir(minikube.KubeConfigPath()), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating directory for kube-config. Error: %+v", err)
+	}
+
+	err = runCommand(minikube.command("update-context"))
+	if err != nil {
+		return fmt.Errorf("error occurred while writing kube-config %q. Error: %+v", minikube.KubeConfigPath(), err)
+	}
+	return nil
+}
+
+// Setup brings minikube to Running state from whatever state it is in.
+// It does nothing when minikube is already running. A stopped or paused minikube is resumed
+// unless ResumeExisting is false, in which case it is deleted and started again.
+// It returns the typed errors of State and Transition, so caller can inspect what went wrong.
 func (minikube Minikube) Setup() error {
-	minikubeStatus, err := minikube.Status()
-
-	logger.PrintfDebugMessageIfError(err, "error occurred while checking minikube status")
-	logger.PrintfDebugMessageIfNotError(err, common.Minikube+" status: %q", minikubeStatus)
-
-	teardownRequired := false
-	startRequired := false
-
-	// I won't use common.Minikube here because I am not really using name here,
-	// this is just another string which appears in the output of minikube status command
-	status, ok := minikubeStatus["minikube"]
-	if !ok {
-		fmt.Println("\"minikube\" not present in status. May be minikube is not accessible. Aborting...")
-		os.Exit(1)
-	}
-	if status == "" { // This means cluster itself is not there
-		fmt.Println("cluster is not up. will start the machine")
-		startRequired = true // So, Start the minikube
-	} else if status == "Stopped" { // Cluster is there but it is stopped
-		fmt.Println("minikube cluster is present but not \"Running\", so will tearing down the machine then start again.")
-		teardownRequired = true // We need to teardown it first
-		startRequired = true    // Then also we need to start the machine
-	} else if status != "Running" { // If cluster is there and machine is not in "Stopped" or "Running" state
-		// Then there is a problem
-		fmt.Printf("minikube is in unknown state. State: %q. Aborting...", status)
-		os.Exit(1)
-	} else { // Else minikube is Running so we need not do anything.
-		fmt.Println("minikube is already Running.")
-	}
-
-	// If we figured out that a teardown is needed then do so
-	if teardownRequired {
-		err = minikube.Teardown()
-		if err != nil {
-			return fmt.Errorf("error occurred while deleting existing machine. Error: %+v", err)
+	state, err := minikube.State()
+	minikube.logger().PrintfDebugMessage(common.Minikube+" state: %q", state)
+	if err != nil {
+		return err
+	}
+
+	if state == StateRunning {
+		minikube.logger().Info("minikube is already Running")
+		return minikube.setEnvironmentKubeConfigPath(minikube.writeKubeConfig())
+	}
+
+	if state != StateAbsent && !minikube.ResumeExisting {
+		minikube.logger().Info("minikube cluster is present but not Running, so will delete it then start again", "state", state)
+		if err = minikube.transition(state, StateAbsent); err != nil {
+			return err
 		}
-		fmt.Println("minikube deleted.")
+		state = StateAbsent
 	}
 
-	// If we figured out that a start is needed then do so
-	if startRequired {
-		minikube.StartMinikube()
+	return minikube.setEnvironmentKubeConfigPath(minikube.transition(state, StateRunning))
+}
+
+// setEnvironmentKubeConfigPath makes kube-config of this run the one CITF uses, if err is nil.
+// It returns err as it is.
+func (minikube Minikube) setEnvironmentKubeConfigPath(err error) error {
+	if err == nil {
+		minikube.conf().SetEnvironmentKubeConfigPath(minikube.KubeConfigPath())
 	}
-	return nil
+	return err
 }
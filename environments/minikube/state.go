@@ -0,0 +1,187 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import "fmt"
+
+// MinikubeState is the state of the minikube cluster
+type MinikubeState string
+
+const (
+	// StateAbsent means minikube cluster does not exist
+	StateAbsent MinikubeState = "Absent"
+	// StateStopped means minikube cluster exists but it is stopped
+	StateStopped MinikubeState = "Stopped"
+	// StateRunning means minikube cluster is running
+	StateRunning MinikubeState = "Running"
+	// StatePaused means minikube cluster exists but it is paused
+	StatePaused MinikubeState = "Paused"
+	// StateUnknown means minikube reported a state which CITF does not understand
+	StateUnknown MinikubeState = "Unknown"
+)
+
+// actions which can be performed on minikube cluster, these are minikube sub-commands
+const (
+	actionStart   = "start"
+	actionStop    = "stop"
+	actionPause   = "pause"
+	actionUnpause = "unpause"
+	actionDelete  = "delete"
+)
+
+// transition is an edge of the state machine of minikube. Performing `action` in `from` state
+// takes minikube to `to` state
+type transition struct {
+	from   MinikubeState
+	to     MinikubeState
+	action string
+}
+
+// transitions are all the allowed transitions of minikube state
+var transitions = []transition{
+	{from: StateAbsent, to: StateRunning, action: actionStart},
+	{from: StateStopped, to: StateRunning, action: actionStart},
+	{from: StatePaused, to: StateRunning, action: actionUnpause},
+	{from: StateRunning, to: StatePaused, action: actionPause},
+	{from: StateRunning, to: StateStopped, action: actionStop},
+	{from: StateRunning, to: StateAbsent, action: actionDelete},
+	{from: StateStopped, to: StateAbsent, action: actionDelete},
+	{from: StatePaused, to: StateAbsent, action: actionDelete},
+}
+
+// findTransition returns the action which takes minikube from `from` state to `to` state
+func findTransition(from, to MinikubeState) (string, bool) {
+	for _, t := range transitions {
+		if t.from == from && t.to == to {
+			return t.action, true
+		}
+	}
+	return "", false
+}
+
+// StatusUnavailableError is returned when state of minikube could not be determined
+// e.g. when minikube is not accessible
+type StatusUnavailableError struct {
+	// Output is the raw output of `minikube status`
+	Output string
+	Err    error
+}
+
+func (err *StatusUnavailableError) Error() string {
+	return fmt.Sprintf("unable to determine minikube state from status %q. May be minikube is not accessible. Error: %+v", err.Output, err.Err)
+}
+
+// UnknownStateError is returned when minikube is in a state which CITF does not understand
+type UnknownStateError struct {
+	// RawState is the state as reported by minikube
+	RawState string
+}
+
+func (err *UnknownStateError) Error() string {
+	return fmt.Sprintf("minikube is in unknown state: %q", err.RawState)
+}
+
+// InvalidTransitionError is returned when there is no way to go from one state to another
+type InvalidTransitionError struct {
+	From MinikubeState
+	To   MinikubeState
+}
+
+func (err *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("minikube can not go from %q state to %q state", err.From, err.To)
+}
+
+// TransitionError is returned when the action to go from one state to another fails
+type TransitionError struct {
+	From   MinikubeState
+	To     MinikubeState
+	Action string
+	Err    error
+}
+
+func (err *TransitionError) Error() string {
+	return fmt.Sprintf("error occurred while running %q to take minikube from %q state to %q state. Error: %+v", err.Action, err.From, err.To, err.Err)
+}
+
+// stateFromStatus returns the state of minikube from its status. Host of a paused minikube is still running,
+// only its api server is reported to be paused.
+func stateFromStatus(status MinikubeStatus) MinikubeState {
+	switch status.Host {
+	case "", "Nonexistent":
+		return StateAbsent
+	case string(StateStopped):
+		return StateStopped
+	case string(StateRunning):
+		if status.APIServer == string(StatePaused) {
+			return StatePaused
+		}
+		return StateRunning
+	default:
+		return StateUnknown
+	}
+}
+
+// State returns the current state of minikube.
+// It returns *StatusUnavailableError if state could not be determined
+// and *UnknownStateError along with StateUnknown if minikube reports a state which is not understood.
+func (minikube Minikube) State() (MinikubeState, error) {
+	status, output, err := minikube.DetailedStatus()
+	if err != nil {
+		return StateUnknown, &StatusUnavailableError{Output: output, Err: err}
+	}
+
+	state := stateFromStatus(status)
+	if state == StateUnknown {
+		return state, &UnknownStateError{RawState: status.Host}
+	}
+	return state, nil
+}
+
+// Transition takes minikube from its current state to the state supplied.
+// It returns *InvalidTransitionError if there is no way to reach that state
+// and *TransitionError if the action failed.
+func (minikube Minikube) Transition(to MinikubeState) error {
+	from, err := minikube.State()
+	if err != nil {
+		return err
+	}
+	return minikube.transition(from, to)
+}
+
+// transition takes minikube from `from` state to `to` state
+func (minikube Minikube) transition(from, to MinikubeState) error {
+	if from == to {
+		return nil
+	}
+
+	action, ok := findTransition(from, to)
+	if !ok {
+		return &InvalidTransitionError{From: from, To: to}
+	}
+
+	minikube.logger().Info("changing state of minikube", "from", from, "action", action, "to", to)
+	var actionErr error
+	switch action {
+	case actionStart:
+		actionErr = minikube.StartMinikube()
+	case actionDelete:
+		actionErr = minikube.teardown()
+	default:
+		actionErr = runCommand(minikube.command(action))
+	}
+	if actionErr != nil {
+		return &TransitionError{From: from, To: to, Action: action, Err: actionErr}
+	}
+	return nil
+}
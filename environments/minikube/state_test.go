@@ -0,0 +1,185 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubCommands replaces the command runners of this package so that `minikube status`
+// returns supplied output and every other command is recorded instead of being run.
+// It returns pointer to the recorded commands and a function to restore the runners.
+func stubCommands(statusOutput string, failCommand string) (*[]string, func()) {
+	var commands []string
+	execBak, runBak := execCommand, runCommand
+
+	execCommand = func(cmd string) (string, error) {
+		return statusOutput, nil
+	}
+	runCommand = func(cmd string) error {
+		commands = append(commands, cmd)
+		if cmd == failCommand {
+			return errors.New("command failed")
+		}
+		return nil
+	}
+	return &commands, func() {
+		execCommand, runCommand = execBak, runBak
+	}
+}
+
+func newTestMinikube(resumeExisting bool) Minikube {
+	minikube := NewMinikube(MinikubeOptions{Driver: "virtualbox"})
+	minikube.Timeout = 10 * time.Millisecond
+	minikube.WaitTimeUnit = time.Millisecond
+	minikube.ResumeExisting = resumeExisting
+	return minikube
+}
+
+func TestState(t *testing.T) {
+	tests := map[string]MinikubeState{
+		statusV16Running:     StateRunning,
+		statusV112Paused:     StatePaused,
+		statusV112NotCreated: StateAbsent,
+		statusV112MultiNode:  StateRunning,
+		statusV022Running:    StateRunning,
+		statusV025NotCreated: StateAbsent,
+		statusV030Stopped:    StateStopped,
+		statusV19Running:     StateRunning,
+	}
+	for statusOutput, want := range tests {
+		_, restore := stubCommands(statusOutput, "")
+		state, err := newTestMinikube(true).State()
+		restore()
+		if err != nil || state != want {
+			t.Errorf("State() with status %q = %q, %v, want %q", statusOutput, state, err, want)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusOutput   string
+		resumeExisting bool
+		failCommand    string   // minikube subcommand which fails
+		wantCommands   []string // minikube subcommands which should run
+		wantErrType    error
+	}{
+		{
+			name:         "running",
+			statusOutput: statusV025Running,
+			wantCommands: []string{"update-context"},
+		},
+		{
+			name:         "absent",
+			statusOutput: statusV025NotCreated,
+			wantCommands: []string{"start --vm-driver=virtualbox"},
+		},
+		{
+			name:           "stopped is resumed",
+			statusOutput:   statusV030Stopped,
+			resumeExisting: true,
+			wantCommands:   []string{"start --vm-driver=virtualbox"},
+		},
+		{
+			name:           "stopped is recreated",
+			statusOutput:   statusV030Stopped,
+			resumeExisting: false,
+			wantCommands:   []string{"delete", "start --vm-driver=virtualbox"},
+		},
+		{
+			name:           "paused is unpaused",
+			statusOutput:   statusV112Paused,
+			resumeExisting: true,
+			wantCommands:   []string{"unpause"},
+		},
+		{
+			name:         "unknown state",
+			statusOutput: "minikube: Saving\n",
+			wantErrType:  &UnknownStateError{},
+		},
+		{
+			name:         "status not available",
+			statusOutput: "something went wrong",
+			wantErrType:  &StatusUnavailableError{},
+		},
+		{
+			name:         "start fails",
+			statusOutput: "minikube:\n",
+			failCommand:  "start --vm-driver=virtualbox",
+			wantCommands: []string{"start --vm-driver=virtualbox"},
+			wantErrType:  &TransitionError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minikube := newTestMinikube(tt.resumeExisting)
+			var wantCommands []string
+			for _, subcommand := range tt.wantCommands {
+				wantCommands = append(wantCommands, minikube.command(subcommand))
+			}
+			commands, restore := stubCommands(tt.statusOutput, minikube.command(tt.failCommand))
+			defer restore()
+
+			err := minikube.Setup()
+			if tt.wantErrType == nil && err != nil {
+				t.Errorf("Setup() returned error: %+v", err)
+			}
+			if tt.wantErrType != nil && reflect.TypeOf(err) != reflect.TypeOf(tt.wantErrType) {
+				t.Errorf("Setup() error = %#v, want error of type %T", err, tt.wantErrType)
+			}
+			if !reflect.DeepEqual(*commands, wantCommands) {
+				t.Errorf("Setup() ran %q, want %q", *commands, wantCommands)
+			}
+		})
+	}
+}
+
+func TestTransitionInvalid(t *testing.T) {
+	err := newTestMinikube(true).transition(StateAbsent, StatePaused)
+	if _, ok := err.(*InvalidTransitionError); !ok {
+		t.Errorf("transition(%q, %q) error = %#v, want *InvalidTransitionError", StateAbsent, StatePaused, err)
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	minikube := newTestMinikube(false)
+	commands, restore := stubCommands("", "")
+	defer restore()
+
+	kubeConfigPath := minikube.KubeConfigPath()
+	if err := os.MkdirAll(filepath.Dir(kubeConfigPath), 0755); err != nil {
+		t.Fatalf("unable to create directory of the run: %+v", err)
+	}
+	if err := ioutil.WriteFile(kubeConfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("unable to write kube-config: %+v", err)
+	}
+
+	if err := minikube.Teardown(); err != nil {
+		t.Errorf("Teardown() returned error: %+v", err)
+	}
+	if want := []string{minikube.command("delete")}; !reflect.DeepEqual(*commands, want) {
+		t.Errorf("Teardown() ran %q, want %q", *commands, want)
+	}
+	if _, err := os.Stat(kubeConfigPath); !os.IsNotExist(err) {
+		t.Errorf("kube-config of the run %q is not removed, error: %v", kubeConfigPath, err)
+	}
+}
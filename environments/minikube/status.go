@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// MinikubeStatus is the status of minikube as reported by `minikube status`
+type MinikubeStatus struct {
+	Name       string `json:"Name"`
+	Host       string `json:"Host"`
+	Kubelet    string `json:"Kubelet"`
+	APIServer  string `json:"APIServer"`
+	Kubeconfig string `json:"Kubeconfig"`
+}
+
+// Map returns the status as a map where keys are "host", "kubelet", "apiserver" and "kubeconfig"
+func (status MinikubeStatus) Map() map[string]string {
+	return map[string]string{
+		"host":       status.Host,
+		"kubelet":    status.Kubelet,
+		"apiserver":  status.APIServer,
+		"kubeconfig": status.Kubeconfig,
+	}
+}
+
+// textStatusKeys maps the keys of text output of different versions of `minikube status`
+// to the pointer of corresponding field of MinikubeStatus.
+// e.g. v0.25 prints "minikube", "cluster" and "kubectl" while v1.x prints "host", "kubelet", "apiserver" and "kubeconfig"
+func textStatusKeys(status *MinikubeStatus) map[string][]*string {
+	return map[string][]*string{
+		"host":       {&status.Host},
+		"minikube":   {&status.Host},
+		"minikubeVM": {&status.Host},
+		"kubelet":    {&status.Kubelet},
+		"apiserver":  {&status.APIServer},
+		"cluster":    {&status.Kubelet, &status.APIServer},
+		"localkube":  {&status.Kubelet, &status.APIServer},
+		"kubeconfig": {&status.Kubeconfig},
+		"kubectl":    {&status.Kubeconfig},
+	}
+}
+
+// parseJSONStatus parses the output of `minikube status --output json`.
+// Multi-node clusters print one status for each node, then status of first node (i.e. control plane) is returned.
+func parseJSONStatus(output string) (MinikubeStatus, error) {
+	output = strings.TrimSpace(output)
+
+	var status MinikubeStatus
+	if strings.HasPrefix(output, "[") {
+		var statuses []MinikubeStatus
+		if err := json.Unmarshal([]byte(output), &statuses); err != nil {
+			return status, err
+		}
+		if len(statuses) == 0 {
+			return status, errors.New("no node in minikube status")
+		}
+		return statuses[0], nil
+	}
+
+	err := json.Unmarshal([]byte(output), &status)
+	if err == nil && status.Host == "" && status.Name == "" {
+		err = errors.New("host not present in minikube status")
+	}
+	return status, err
+}
+
+// parseTextStatus parses the text output of `minikube status` i.e. "key: value" lines.
+// It returns error if none of the known keys is present in output.
+func parseTextStatus(output string) (MinikubeStatus, error) {
+	var status MinikubeStatus
+	keys := textStatusKeys(&status)
+
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		keyval := strings.SplitN(line, ":", 2)
+		fields, ok := keys[strings.TrimSpace(keyval[0])]
+		if !ok {
+			continue
+		}
+		found = true
+
+		value := ""
+		if len(keyval) == 2 {
+			value = strings.TrimSpace(keyval[1])
+		}
+		for _, field := range fields {
+			*field = value
+		}
+	}
+
+	if !found {
+		return status, errors.New("no known key present in minikube status")
+	}
+	return status, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package minikube
+
+import "testing"
+
+// Outputs of `minikube status` recorded from minikube releases, `--output json` where it is supported
+const (
+	statusV16Running     = `{"Name":"minikube","Host":"Running","Kubelet":"Running","APIServer":"Running","Kubeconfig":"Configured"}`
+	statusV112Paused     = `{"Name":"minikube","Host":"Running","Kubelet":"Stopped","APIServer":"Paused","Kubeconfig":"Configured","Worker":false}` + "\n"
+	statusV112NotCreated = `{"Name":"minikube","Host":"Nonexistent","Kubelet":"Nonexistent","APIServer":"Nonexistent","Kubeconfig":"Nonexistent"}`
+	statusV112MultiNode  = `[{"Name":"minikube","Host":"Running","Kubelet":"Running","APIServer":"Running","Kubeconfig":"Configured","Worker":false},` +
+		`{"Name":"minikube-m02","Host":"Running","Kubelet":"Running","Worker":true}]`
+	statusV022Running    = "minikubeVM: Running\nlocalkube: Running\n"
+	statusV025Running    = "minikube: Running\ncluster: Running\nkubectl: Correctly Configured: pointing to minikube-vm at 10.0.2.15\n"
+	statusV025NotCreated = "minikube:\ncluster:\nkubectl:\n"
+	statusV030Stopped    = "host: Stopped\nkubelet:\napiserver:\nkubectl:\n"
+	statusV19Running     = "minikube\ntype: Control Plane\nhost: Running\nkubelet: Running\napiserver: Running\nkubeconfig: Configured\n"
+)
+
+func TestParseJSONStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    MinikubeStatus
+		wantErr bool
+	}{
+		{
+			name:   "v1.6 running",
+			output: statusV16Running,
+			want:   MinikubeStatus{Name: "minikube", Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Configured"},
+		},
+		{
+			name:   "v1.12 paused",
+			output: statusV112Paused,
+			want:   MinikubeStatus{Name: "minikube", Host: "Running", Kubelet: "Stopped", APIServer: "Paused", Kubeconfig: "Configured"},
+		},
+		{
+			name:   "v1.12 not created",
+			output: statusV112NotCreated,
+			want:   MinikubeStatus{Name: "minikube", Host: "Nonexistent", Kubelet: "Nonexistent", APIServer: "Nonexistent", Kubeconfig: "Nonexistent"},
+		},
+		{
+			name:   "v1.12 multi-node",
+			output: statusV112MultiNode,
+			want:   MinikubeStatus{Name: "minikube", Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Configured"},
+		},
+		{
+			name:    "v0.25 does not support json",
+			output:  "",
+			wantErr: true,
+		},
+		{
+			name:    "text output",
+			output:  "host: Running\nkubelet: Running\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONStatus(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJSONStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseJSONStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTextStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    MinikubeStatus
+		wantErr bool
+	}{
+		{
+			name:   "v0.22 running",
+			output: statusV022Running,
+			want:   MinikubeStatus{Host: "Running", Kubelet: "Running", APIServer: "Running"},
+		},
+		{
+			name:   "v0.25 running",
+			output: statusV025Running,
+			want: MinikubeStatus{Host: "Running", Kubelet: "Running", APIServer: "Running",
+				Kubeconfig: "Correctly Configured: pointing to minikube-vm at 10.0.2.15"},
+		},
+		{
+			name:   "v0.25 not created",
+			output: statusV025NotCreated,
+			want:   MinikubeStatus{},
+		},
+		{
+			name:   "v0.30 stopped",
+			output: statusV030Stopped,
+			want:   MinikubeStatus{Host: "Stopped"},
+		},
+		{
+			name:   "v1.9 running",
+			output: statusV19Running,
+			want:   MinikubeStatus{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Configured"},
+		},
+		{
+			name:    "unrecognised output",
+			output:  "E1017 10:00:00.000000 1234 status.go:42] something went wrong\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTextStatus(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTextStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseTextStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,8 +13,31 @@ limitations under the License.
 
 package minikube
 
-// Teardown deletes minikube
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/openebs/CITF/environments"
+)
+
+// Teardown deletes minikube and the kube-config written for the current run,
+// unless the teardown policy of the configuration keeps it (see environments.TeardownIfAllowed)
 func (minikube Minikube) Teardown() error {
+	return environments.TeardownIfAllowed(minikube, minikube.conf(), minikube.teardown)
+}
+
+// teardown deletes minikube and the kube-config written for the current run, regardless of teardown policy
+func (minikube Minikube) teardown() error {
 	// Caller of this function should have proper rights to delete minikube
-	return runCommand("minikube delete")
+	if err := runCommand(minikube.command("delete")); err != nil {
+		return err
+	}
+
+	kubeConfigPath := minikube.KubeConfigPath()
+	if err := os.Remove(kubeConfigPath); err != nil && !os.IsNotExist(err) {
+		minikube.logger().PrintErrorf(err, "error removing %q", kubeConfigPath)
+	}
+	// directory of the run is removed too unless something else of the run is still in it
+	os.Remove(filepath.Dir(kubeConfigPath))
+	return nil
 }
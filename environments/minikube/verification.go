@@ -14,83 +14,107 @@ limitations under the License.
 package minikube
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path"
-	"strings"
-	"time"
+	"path/filepath"
+
+	"github.com/openebs/CITF/utils/wait"
 )
 
-// waitForDotKubeDirToBeCreated waits for `.kube` to be created
-func (minikube Minikube) waitForDotKubeDirToBeCreated() {
-	homeDir := os.Getenv("HOME")
+// poll checks the condition once every minikube.WaitTimeUnit until it is met or minikube.Timeout passes
+func (minikube Minikube) poll(condition wait.Condition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), minikube.Timeout)
+	defer cancel()
 
-	fmt.Println("Waiting for `.kube` to be created...")
-	for {
-		if _, err := os.Stat(path.Join(homeDir, ".kube")); err == nil {
-			fmt.Println(path.Join(homeDir, ".kube") + " created.")
-			break
-		} else if _, err := os.Stat("/root/.kube"); err == nil {
-			fmt.Println("/root/.kube created.")
-			break
-		}
-		time.Sleep(minikube.WaitTimeUnit)
+	return wait.Poll(ctx, wait.Options{Interval: minikube.WaitTimeUnit}, condition)
+}
+
+// waitForPath waits until `path` is created or minikube.Timeout passes
+func (minikube Minikube) waitForPath(path string) error {
+	err := minikube.poll(func(context.Context) (bool, string, error) {
+		_, err := os.Stat(path)
+		return err == nil, fmt.Sprint(err), nil
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for %q to be created: %v", path, err)
 	}
+	minikube.logger().Info("created", "path", path)
+	return nil
+}
+
+// waitForKubeConfigToBeCreated waits for kube-config of this run to be created, until timeout
+func (minikube Minikube) waitForKubeConfigToBeCreated() error {
+	minikube.logger().Info("waiting for kube-config to be created", "path", minikube.KubeConfigPath())
+	return minikube.waitForPath(minikube.KubeConfigPath())
 }
 
-// waitForDotMinikubeDirToBeCreated waits for `.minikube` to be created
-func (minikube Minikube) waitForDotMinikubeDirToBeCreated() {
+// waitForDotMinikubeDirToBeCreated waits for `.minikube` to be created, until timeout
+func (minikube Minikube) waitForDotMinikubeDirToBeCreated() error {
 	homeDir := os.Getenv("HOME")
 
-	fmt.Println("Waiting for `.minikube` to be created...")
-	for {
-		if _, err := os.Stat(path.Join(homeDir, ".minikube")); err == nil {
-			fmt.Println(path.Join(homeDir, ".minikube") + " created.")
-			break
-		} else if _, err := os.Stat("/root/.minikube"); err == nil {
-			fmt.Println("/root/.minikube created.")
-			break
+	minikube.logger().Info("waiting for `.minikube` to be created")
+	err := minikube.poll(func(context.Context) (bool, string, error) {
+		for _, path := range []string{filepath.Join(homeDir, ".minikube"), "/root/.minikube"} {
+			if _, err := os.Stat(path); err == nil {
+				minikube.logger().Info("created", "path", path)
+				return true, "", nil
+			}
 		}
-		time.Sleep(minikube.WaitTimeUnit)
+		return false, "`.minikube` is not present", nil
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for `.minikube` to be created: %v", err)
 	}
+	return nil
 }
 
-// checkStatus checks minikube status and parse it to a map .
-// :return:   map: minikube status parsed into dict.
-//          error: if any error occurs, otherwise nil
-// Note: error can come when machine is stopped too. But in this case status will be filled too
-func (minikube Minikube) checkStatus() (map[string]string, error) {
+// checkStatus checks minikube status and parse it to MinikubeStatus.
+// It first tries `minikube status --output json`, if that can not be parsed
+// (e.g. older minikube which does not support it) it falls back to parsing the text output.
+// :return: MinikubeStatus: parsed status
+//          string: raw output of the last command
+//          error: if status could not be parsed, otherwise nil
+// Note: minikube exits with non-zero code when machine is stopped too, so exit code is not considered.
+func (minikube Minikube) checkStatus() (MinikubeStatus, string, error) {
 	// Caller of this function should have proper rights to check minikube status
-	command := "minikube status"
-	statusStr, err := execCommand(command)
+	command := minikube.command("status")
 
-	status := map[string]string{}
-	for _, line := range strings.Split(strings.TrimSpace(statusStr), "\n") {
-		keyval := strings.SplitN(line, ":", 2)
-		if len(keyval) == 1 {
-			status[strings.TrimSpace(keyval[0])] = ""
-		} else {
-			status[strings.TrimSpace(keyval[0])] = strings.TrimSpace(keyval[1])
+	output, err := execCommand(command + " --output json")
+	minikube.logger().PrintfDebugMessageIfError(err, "%q exited with error", command+" --output json")
+	status, parseErr := parseJSONStatus(output)
+	if parseErr == nil {
+		return status, output, nil
+	}
+
+	output, err = execCommand(command)
+	minikube.logger().PrintfDebugMessageIfError(err, "%q exited with error", command)
+	status, parseErr = parseTextStatus(output)
+	if parseErr != nil {
+		if err != nil {
+			parseErr = fmt.Errorf("%+v. Error in command: %+v", parseErr, err)
 		}
+		return status, output, parseErr
 	}
-	return status, err
+	return status, output, nil
 }
 
-// Status checks the status and in case where it does not find "minikube:" in status string,
-// it retries until timeout. Then it returns the last status as well as the last error.
-func (minikube Minikube) Status() (map[string]string, error) {
-	startTime := time.Now()
-	var status map[string]string
+// DetailedStatus checks the status and in case where it could not parse the status,
+// it retries until timeout. Then it returns the last status, raw output as well as the last error.
+func (minikube Minikube) DetailedStatus() (MinikubeStatus, string, error) {
+	var status MinikubeStatus
+	var output string
 	var err error
-	for time.Since(startTime) < minikube.Timeout {
-		status, err = minikube.checkStatus()
-		// I won't use common.Minikube here because I am not really using name here,
-		// this is just another string which appears in the output of minikube status command
-		if _, ok := status["minikube"]; !ok {
-			time.Sleep(minikube.WaitTimeUnit)
-		} else {
-			break
-		}
-	}
-	return status, err
+	minikube.poll(func(context.Context) (bool, string, error) {
+		status, output, err = minikube.checkStatus()
+		return err == nil, fmt.Sprint(err), nil
+	})
+	return status, output, err
+}
+
+// Status returns the status of minikube as a map where keys are "host", "kubelet", "apiserver" and "kubeconfig".
+// It retries until timeout if status could not be parsed.
+func (minikube Minikube) Status() (map[string]string, error) {
+	status, _, err := minikube.DetailedStatus()
+	return status.Map(), err
 }
@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openebs/CITF/config"
+)
+
+// Factory returns a new instance of an Environment which reads its configurations from supplied Config
+type Factory func(conf *config.Config) (Environment, error)
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]Factory{}
+)
+
+// Register makes an environment available under the supplied name.
+// It is meant to be called from the `init` function of the package which
+// provides the environment, so that importing that package is enough to use it.
+// It panics if name is empty, factory is nil or name is already registered.
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if name == "" {
+		panic("environments: Register called with empty name")
+	}
+	if factory == nil {
+		panic("environments: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("environments: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered returns the sorted list of the names of all the registered environments
+func Registered() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns a new instance of the environment registered under the supplied name,
+// which reads its configurations from supplied Config. nil Config means package level configurations.
+// If no such environment is registered, returned error lists the registered ones.
+func Get(name string, conf *config.Config) (Environment, error) {
+	registryMutex.RLock()
+	factory, ok := registry[name]
+	registryMutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("platform: %q is not supported by CITF, registered platforms are: [%s]", name, strings.Join(Registered(), ", "))
+	}
+	if conf == nil {
+		conf = config.Global()
+	}
+	return factory(conf)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environments
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openebs/CITF/config"
+)
+
+// fakeEnvironment is an Environment which does nothing
+type fakeEnvironment struct {
+	name string
+}
+
+func (fake fakeEnvironment) Name() string                       { return fake.name }
+func (fake fakeEnvironment) Setup() error                       { return nil }
+func (fake fakeEnvironment) Status() (map[string]string, error) { return nil, nil }
+func (fake fakeEnvironment) Teardown() error                    { return nil }
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"fake-b", "fake-a"} {
+		envName := name
+		Register(envName, func(conf *config.Config) (Environment, error) {
+			return fakeEnvironment{name: envName + "/" + conf.Environment()}, nil
+		})
+	}
+
+	if got, want := Registered(), []string{"fake-a", "fake-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Registered() = %v, want %v", got, want)
+	}
+
+	conf, err := config.NewConfig("")
+	if err != nil {
+		t.Fatalf("config.NewConfig() returned error: %+v", err)
+	}
+	environ, err := Get("fake-a", conf)
+	if err != nil {
+		t.Fatalf("Get(%q) returned error: %+v", "fake-a", err)
+	}
+	if want := "fake-a/" + conf.Environment(); environ.Name() != want {
+		t.Errorf("Get(%q).Name() = %q, want %q", "fake-a", environ.Name(), want)
+	}
+
+	_, err = Get("not-registered", conf)
+	if err == nil || !strings.Contains(err.Error(), "fake-a, fake-b") {
+		t.Errorf("Get(%q) error = %v, want it to list registered platforms", "not-registered", err)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	Register("fake-duplicate", func(*config.Config) (Environment, error) { return fakeEnvironment{}, nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register() did not panic for duplicate name")
+		}
+	}()
+	Register("fake-duplicate", func(*config.Config) (Environment, error) { return fakeEnvironment{}, nil })
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environments
+
+import (
+	"github.com/openebs/CITF/config"
+)
+
+// TeardownWithPolicy tears the environment down only if the teardown policy of conf allows it,
+// `succeeded` tells whether the tests passed. nil Config means package level configurations.
+// When it keeps the environment, it logs the kube-config to reach the cluster and `preservedNamespaces`
+// so that the failure can be inspected. It returns whether the environment was torn down.
+func TeardownWithPolicy(environ Environment, conf *config.Config, succeeded bool, preservedNamespaces []string) (bool, error) {
+	return teardownWithPolicy(environ, conf, succeeded, preservedNamespaces, environ.Teardown)
+}
+
+// TeardownIfAllowed is meant for `Teardown()` of the environments, so that they honour the teardown policy
+// of conf even if they are torn down directly: it calls teardown (which tears the environment down regardless
+// of the policy) only if the policy allows it, otherwise it logs like TeardownWithPolicy.
+// Teardown() does not know whether the tests passed, so they are taken as passed i.e. only `never` keeps
+// the environment; TeardownWithPolicy (or CITF.TeardownEnvironment) keeps it on failure with `on-success`.
+func TeardownIfAllowed(environ Environment, conf *config.Config, teardown func() error) error {
+	_, err := teardownWithPolicy(environ, conf, true, nil, teardown)
+	return err
+}
+
+// teardownWithPolicy is TeardownWithPolicy which tears the environment down with teardown
+func teardownWithPolicy(environ Environment, conf *config.Config, succeeded bool, preservedNamespaces []string, teardown func() error) (bool, error) {
+	if conf == nil {
+		conf = config.Global()
+	}
+	if conf.ShouldTeardown(succeeded) {
+		return true, teardown()
+	}
+
+	kubeConfigPath := conf.KubeConfigPath()
+	if provider, ok := environ.(KubeConfigProvider); ok {
+		kubeConfigPath = provider.KubeConfigPath()
+	}
+	conf.Logger().Warn("skipping teardown of environment as per teardown policy",
+		"environment", environ.Name(),
+		"teardownPolicy", conf.TeardownPolicy(),
+		"succeeded", succeeded,
+		"kubeConfig", kubeConfigPath,
+		"preservedNamespaces", preservedNamespaces)
+	return false, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environments
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/utils/log"
+)
+
+// teardownCountingEnvironment counts how many times it is torn down
+type teardownCountingEnvironment struct {
+	fakeEnvironment
+	teardowns *int
+}
+
+func (environ teardownCountingEnvironment) Teardown() error {
+	*environ.teardowns++
+	return nil
+}
+
+func (environ teardownCountingEnvironment) KubeConfigPath() string {
+	return "/tmp/citf-run/fake.kubeconfig"
+}
+
+func TestTeardownWithPolicy(t *testing.T) {
+	environContent, environSet := os.LookupEnv("CITF_CONF_TEARDOWNPOLICY")
+	if environSet {
+		defer os.Setenv("CITF_CONF_TEARDOWNPOLICY", environContent)
+	} else {
+		defer os.Unsetenv("CITF_CONF_TEARDOWNPOLICY")
+	}
+	logger := config.Global().Logger()
+	defer logger.SetOptions(logger.Options())
+
+	tests := []struct {
+		policy       string
+		succeeded    bool
+		wantTeardown bool
+	}{
+		{policy: config.TeardownAlways, succeeded: false, wantTeardown: true},
+		{policy: config.TeardownNever, succeeded: true, wantTeardown: false},
+		{policy: config.TeardownOnSuccess, succeeded: true, wantTeardown: true},
+		{policy: config.TeardownOnSuccess, succeeded: false, wantTeardown: false},
+	}
+	for _, tt := range tests {
+		os.Setenv("CITF_CONF_TEARDOWNPOLICY", tt.policy)
+		report := &bytes.Buffer{}
+		logger.SetOptions(log.Options{Level: log.LevelInfo, Sinks: []io.Writer{report}})
+
+		teardowns := 0
+		environ := teardownCountingEnvironment{fakeEnvironment: fakeEnvironment{name: "fake"}, teardowns: &teardowns}
+		tornDown, err := TeardownWithPolicy(environ, nil, tt.succeeded, []string{"citf-test-abcde"})
+		if err != nil {
+			t.Errorf("TeardownWithPolicy() with policy %q returned error: %+v", tt.policy, err)
+		}
+		if tornDown != tt.wantTeardown || (teardowns == 1) != tt.wantTeardown {
+			t.Errorf("TeardownWithPolicy() with policy %q and succeeded %t tore down %d times, returned %t, want %t",
+				tt.policy, tt.succeeded, teardowns, tornDown, tt.wantTeardown)
+		}
+		if !tt.wantTeardown {
+			for _, want := range []string{"/tmp/citf-run/fake.kubeconfig", "citf-test-abcde"} {
+				if !strings.Contains(report.String(), want) {
+					t.Errorf("report of preserved environment %q does not contain %q", report.String(), want)
+				}
+			}
+		}
+	}
+}
+
+func TestTeardownIfAllowed(t *testing.T) {
+	environContent, environSet := os.LookupEnv("CITF_CONF_TEARDOWNPOLICY")
+	if environSet {
+		defer os.Setenv("CITF_CONF_TEARDOWNPOLICY", environContent)
+	} else {
+		defer os.Unsetenv("CITF_CONF_TEARDOWNPOLICY")
+	}
+
+	tests := map[string]bool{
+		config.TeardownAlways:    true,
+		config.TeardownNever:     false,
+		config.TeardownOnSuccess: true,
+	}
+	for policy, wantTeardown := range tests {
+		os.Setenv("CITF_CONF_TEARDOWNPOLICY", policy)
+
+		teardowns := 0
+		environ := fakeEnvironment{name: "fake"}
+		err := TeardownIfAllowed(environ, nil, func() error {
+			teardowns++
+			return nil
+		})
+		if err != nil || (teardowns == 1) != wantTeardown {
+			t.Errorf("TeardownIfAllowed() with policy %q tore down %d times and returned %v, want teardown %t",
+				policy, teardowns, err, wantTeardown)
+		}
+	}
+}
@@ -12,12 +12,15 @@ import (
 
 var CitfInstance citf.CITF
 
+// suiteSucceeded becomes false when any test fails, so that teardown policy `on-success` keeps the cluster
+var suiteSucceeded = true
+
 func TestIntegrationExample(t *testing.T) {
 	RegisterFailHandler(Fail)
 
 	var err error
 	// Initializing CITF without config file.
-	// Also We should not include K8S as currently we don't have kubernetes environment setup
+	// K8S is not included as environment is not set up yet, `SetupEnvironment` creates it
 	CitfInstance, err = citf.NewCITF(citfoptions.CreateOptionsIncludeAllButK8s(""))
 	Expect(err).NotTo(HaveOccurred())
 
@@ -27,20 +30,13 @@ func TestIntegrationExample(t *testing.T) {
 var _ = BeforeSuite(func() {
 
 	// Setting up the default Platform i.e minikube
-	err := CitfInstance.Environment.Setup()
-	Expect(err).NotTo(HaveOccurred())
-
-	// You have to update the K8s config when environment has been set up
-	// this extra step will be unsolicited in upcoming changes.
-	err = CitfInstance.Reload(citfoptions.CreateOptionsIncludeAll(""))
+	// It returns once the cluster is ready, with K8S created for it
+	err := CitfInstance.SetupEnvironment()
 	Expect(err).NotTo(HaveOccurred())
 	Expect(CitfInstance.K8S.Config).NotTo(BeNil())
 	Expect(CitfInstance.K8S.Clientset).NotTo(BeNil())
 	Expect(CitfInstance.K8S.OpenebsClientSet).NotTo(BeNil())
 
-	// Wait until platform is up
-	time.Sleep(30 * time.Second)
-
 	err = CitfInstance.K8S.YAMLApply("./nginx-rc.yaml")
 	Expect(err).NotTo(HaveOccurred())
 
@@ -48,10 +44,16 @@ var _ = BeforeSuite(func() {
 	time.Sleep(30 * time.Second)
 })
 
+var _ = AfterEach(func() {
+	if CurrentGinkgoTestDescription().Failed {
+		suiteSucceeded = false
+	}
+})
+
 var _ = AfterSuite(func() {
 
-	// Tear Down the Platform
-	err := CitfInstance.Environment.Teardown()
+	// Tear Down the Platform, as per teardown policy
+	err := CitfInstance.TeardownEnvironment(suiteSucceeded)
 	Expect(err).NotTo(HaveOccurred())
 })
 
@@ -0,0 +1,335 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/openebs/CITF/common"
+	sysutil "github.com/openebs/CITF/utils/system"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// AppliedDocument is the result of applying (or deleting) one object of a manifest
+type AppliedDocument struct {
+	// Index is the position of the document in the manifest, starting at 0.
+	// Items of a List document share the index of the List.
+	Index int
+	// Object is the object as returned by the api server when applied, otherwise it is as read from the manifest.
+	// It is nil if the document could not be parsed.
+	Object *unstructured.Unstructured
+	// Created tells whether the object was created, otherwise it was present and is patched
+	Created bool
+	// Err is the error in applying this document, nil if it is applied
+	Err error
+}
+
+func (document AppliedDocument) String() string {
+	if document.Object == nil {
+		return fmt.Sprintf("document %d", document.Index)
+	}
+	name := document.Object.GetName()
+	if document.Object.GetNamespace() != "" {
+		name = document.Object.GetNamespace() + "/" + name
+	}
+	return fmt.Sprintf("document %d (%s %s)", document.Index, document.Object.GetKind(), name)
+}
+
+// ApplyError is returned when one or more documents of a manifest could not be applied
+type ApplyError struct {
+	// Failed are the documents which could not be applied
+	Failed []AppliedDocument
+	// Total is the number of objects in the manifest
+	Total int
+}
+
+func (err *ApplyError) Error() string {
+	messages := make([]string, 0, len(err.Failed))
+	for _, document := range err.Failed {
+		messages = append(messages, fmt.Sprintf("%s: %v", document, document.Err))
+	}
+	return fmt.Sprintf("failed applying %d of %d objects: %s", len(err.Failed), err.Total, strings.Join(messages, "; "))
+}
+
+// splitManifest parses every document of the multi-document YAML (or JSON) manifest as an object.
+// Empty documents are skipped, items of a List are returned as objects of their own.
+// Documents which could not be parsed are returned with nil Object and non-nil Err.
+func splitManifest(manifest []byte) ([]AppliedDocument, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+
+	var documents []AppliedDocument
+	index := -1
+	for {
+		documentBytes, err := reader.Read()
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return documents, fmt.Errorf("error reading document %d of manifest. Error: %+v", index+1, err)
+		}
+
+		jsonBytes, err := yaml.ToJSON(documentBytes)
+		if err == nil {
+			if trimmed := bytes.TrimSpace(jsonBytes); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+				// empty document e.g. after the last `---`
+				continue
+			}
+		}
+		index++
+		if err != nil {
+			documents = append(documents, AppliedDocument{Index: index, Err: fmt.Errorf("error parsing document. Error: %+v", err)})
+			continue
+		}
+
+		object := &unstructured.Unstructured{}
+		if err = object.UnmarshalJSON(jsonBytes); err != nil {
+			documents = append(documents, AppliedDocument{Index: index, Err: fmt.Errorf("error parsing document as kubernetes object. Error: %+v", err)})
+			continue
+		}
+		if !object.IsList() {
+			documents = append(documents, AppliedDocument{Index: index, Object: object})
+			continue
+		}
+
+		err = object.EachListItem(func(item runtime.Object) error {
+			documents = append(documents, AppliedDocument{Index: index, Object: item.(*unstructured.Unstructured)})
+			return nil
+		})
+		if err != nil {
+			documents = append(documents, AppliedDocument{Index: index, Err: fmt.Errorf("error reading items of list. Error: %+v", err)})
+		}
+	}
+}
+
+// manifestClient applies or deletes objects with the dynamic client, resources of the objects are found with mapper
+type manifestClient struct {
+	k8s    K8S
+	mapper meta.RESTMapper
+	client dynamic.Interface
+	// refreshMapper returns a mapper with latest resources of the cluster, e.g. after a CRD is created
+	refreshMapper func() (meta.RESTMapper, error)
+}
+
+// resourceFor returns the client of the resource of the object. Namespace of the object is set to default
+// if it is namespaced and has none, and is cleared if it is cluster scoped.
+func (client *manifestClient) resourceFor(object *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := object.GroupVersionKind()
+	mapping, err := client.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) && client.refreshMapper != nil {
+		// resource may have been just created by a CRD in the same manifest
+		if mapper, refreshErr := client.refreshMapper(); refreshErr == nil {
+			client.mapper = mapper
+			mapping, err = client.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding resource of %q. Error: %+v", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		object.SetNamespace("")
+		return client.client.Resource(mapping.Resource), nil
+	}
+	if object.GetNamespace() == "" {
+		object.SetNamespace(meta_v1.NamespaceDefault)
+	}
+	return client.client.Resource(mapping.Resource).Namespace(object.GetNamespace()), nil
+}
+
+// apply creates the object if it is not present, otherwise patches it with the object in the manifest (JSON merge patch),
+// so fields which are not in the manifest, e.g. those set by the api server, are kept
+func (client *manifestClient) apply(document *AppliedDocument) {
+	object := document.Object
+	resource, err := client.resourceFor(object)
+	if err != nil {
+		document.Err = err
+		return
+	}
+	existing, err := resource.Get(object.GetName(), meta_v1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		client.k8s.stamp(object)
+		applied, err := resource.Create(object)
+		if err != nil {
+			document.Err = fmt.Errorf("error creating. Error: %+v", err)
+			return
+		}
+		document.Object, document.Created = applied, true
+		if trackedKind(applied.GetKind()) {
+			client.k8s.track(applied.GetKind(), applied.GetNamespace(), applied.GetName())
+		}
+	case err != nil:
+		document.Err = fmt.Errorf("error getting present object. Error: %+v", err)
+	default:
+		// object is not created by this run, so it must not be labelled as if it were
+		keepCITFLabels(object, existing)
+		patch, err := object.MarshalJSON()
+		if err != nil {
+			document.Err = fmt.Errorf("error encoding patch. Error: %+v", err)
+			return
+		}
+		applied, err := resource.Patch(object.GetName(), types.MergePatchType, patch)
+		if err != nil {
+			document.Err = fmt.Errorf("error patching. Error: %+v", err)
+			return
+		}
+		document.Object = applied
+	}
+}
+
+// keepCITFLabels sets the labels of CITF (run ID, created-at) of object to those of existing, i.e. the present object.
+// Otherwise patching an object which was not created by the run would make teardown or garbage collection delete it.
+func keepCITFLabels(object, existing meta_v1.Object) {
+	labels := map[string]string{}
+	for key, value := range object.GetLabels() {
+		if !strings.HasPrefix(key, common.LabelPrefix) {
+			labels[key] = value
+		}
+	}
+	for key, value := range existing.GetLabels() {
+		if strings.HasPrefix(key, common.LabelPrefix) {
+			labels[key] = value
+		}
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	object.SetLabels(labels)
+}
+
+// applyDocuments applies every parsed document in order and returns them along with ApplyError if any failed
+func (client *manifestClient) applyDocuments(documents []AppliedDocument) ([]AppliedDocument, error) {
+	applyErr := &ApplyError{Total: len(documents)}
+	for i := range documents {
+		if documents[i].Err == nil {
+			client.apply(&documents[i])
+		}
+		if documents[i].Err != nil {
+			client.k8s.Logger.Error("failed applying", "document", documents[i].String(), "error", documents[i].Err)
+			applyErr.Failed = append(applyErr.Failed, documents[i])
+			continue
+		}
+		client.k8s.Logger.Debug("applied", "document", documents[i].String(), "created", documents[i].Created)
+	}
+	if len(applyErr.Failed) != 0 {
+		return documents, applyErr
+	}
+	return documents, nil
+}
+
+// trackedKind tells whether the objects of the kind are tracked by Tracker
+func trackedKind(kind string) bool {
+	for _, trackedKind := range deletionOrder {
+		if trackedKind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// discoveryRESTMapper returns a mapper of the resources served by the api server of k8s at present
+func (k8s K8S) discoveryRESTMapper() (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(k8s.Clientset.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("error discovering resources of api server. Error: %+v", err)
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// newManifestClient returns a manifestClient which uses the dynamic client of k8s and discovers its resources
+func (k8s K8S) newManifestClient() (*manifestClient, error) {
+	if k8s.DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client is not set in K8S")
+	}
+	mapper, err := k8s.discoveryRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &manifestClient{
+		k8s:           k8s,
+		mapper:        mapper,
+		client:        k8s.DynamicClient,
+		refreshMapper: k8s.discoveryRESTMapper,
+	}, nil
+}
+
+// ApplyManifest creates or updates every object of the multi-document YAML (or JSON) manifest, in order,
+// through the api server i.e. without kubectl. Resource of each object, including OpenEBS CRs, is found
+// with the discovery client, so any kind served by the cluster can be applied. Objects which are present
+// are replaced by those in the manifest. Created objects are labelled with the run ID and tracked like
+// those created by other methods of K8S.
+// It returns every object with the result of applying it, and *ApplyError if any of them failed.
+func (k8s K8S) ApplyManifest(manifest []byte) ([]AppliedDocument, error) {
+	documents, err := splitManifest(manifest)
+	if err != nil {
+		return documents, err
+	}
+	client, err := k8s.newManifestClient()
+	if err != nil {
+		return documents, err
+	}
+	return client.applyDocuments(documents)
+}
+
+// ApplyManifestReader is same as ApplyManifest except that the manifest is read from reader
+func (k8s K8S) ApplyManifestReader(reader io.Reader) ([]AppliedDocument, error) {
+	manifest, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest. Error: %+v", err)
+	}
+	return k8s.ApplyManifest(manifest)
+}
+
+// YAMLApplyAPI applies the YAML file specified by the argument through the api server, see ApplyManifest.
+//    :param str yamlPath: Path of the yaml file that is to be applied.
+//    :return: []AppliedDocument: every object of the file along with the result of applying it
+//             error: *ApplyError if any object could not be applied, otherwise `nil`
+func (k8s K8S) YAMLApplyAPI(yamlPath string) ([]AppliedDocument, error) {
+	manifest, err := ioutil.ReadFile(yamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q. Error: %+v", yamlPath, err)
+	}
+	return k8s.ApplyManifest(manifest)
+}
+
+// YAMLApply apply the yaml specified by the argument through the api server, see YAMLApplyAPI.
+//    :param str yamlPath: Path of the yaml file that is to be applied.
+func (k8s K8S) YAMLApply(yamlPath string) error {
+	_, err := k8s.YAMLApplyAPI(yamlPath)
+	return err
+}
+
+// YAMLApplyKubectl apply the yaml specified by the argument with `kubectl apply -f`.
+// It is a fallback for what YAMLApply can not do, e.g. three way merge of the objects which are present.
+//    :param str yamlPath: Path of the yaml file that is to be applied.
+func (k8s K8S) YAMLApplyKubectl(yamlPath string) error {
+	output, err := sysutil.ExecCommand(common.Kubectl + " apply -f " + yamlPath)
+	if err != nil {
+		return fmt.Errorf("failed applying %s with %s. Error: %+v, Output: %s", yamlPath, common.Kubectl, err, output)
+	}
+	return nil
+}
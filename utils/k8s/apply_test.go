@@ -0,0 +1,281 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openebs/CITF/common"
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+---
+apiVersion: openebs.io/v1alpha1
+kind: StoragePoolClaim
+metadata:
+  name: cstor-pool
+spec:
+  type: disk
+---
+# only a comment
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unknown
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+    namespace: apps
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+    namespace: apps
+`
+
+func TestSplitManifest(t *testing.T) {
+	documents, err := splitManifest([]byte(testManifest + "---\nkind: [broken\n"))
+	if err != nil {
+		t.Fatalf("splitManifest() returned error: %+v", err)
+	}
+
+	expected := []string{
+		"document 0 (ConfigMap settings)",
+		"document 1 (StoragePoolClaim cstor-pool)",
+		"document 2 (Unknown unknown)",
+		"document 3 (ConfigMap apps/first)",
+		"document 3 (ConfigMap apps/second)",
+		"document 4",
+	}
+	if len(documents) != len(expected) {
+		t.Fatalf("splitManifest() returned %d documents %v, expected %d", len(documents), documents, len(expected))
+	}
+	for i, document := range documents {
+		if document.String() != expected[i] {
+			t.Errorf("document %d is %q, expected %q", i, document.String(), expected[i])
+		}
+	}
+	if documents[5].Err == nil {
+		t.Errorf("expected error for the document which is not valid YAML")
+	}
+}
+
+// testRESTMapper maps core ConfigMaps, Services and OpenEBS StoragePoolClaims, like discovery of a cluster with OpenEBS
+func testRESTMapper() meta.RESTMapper {
+	return restmapper.NewDiscoveryRESTMapper([]*restmapper.APIGroupResources{
+		{
+			Group: meta_v1.APIGroup{
+				Name:             "",
+				Versions:         []meta_v1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: meta_v1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]meta_v1.APIResource{
+				"v1": {
+					{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+					{Name: "services", Kind: "Service", Namespaced: true},
+				},
+			},
+		},
+		{
+			Group: meta_v1.APIGroup{
+				Name:             "openebs.io",
+				Versions:         []meta_v1.GroupVersionForDiscovery{{GroupVersion: "openebs.io/v1alpha1", Version: "v1alpha1"}},
+				PreferredVersion: meta_v1.GroupVersionForDiscovery{GroupVersion: "openebs.io/v1alpha1", Version: "v1alpha1"},
+			},
+			VersionedResources: map[string][]meta_v1.APIResource{
+				"v1alpha1": {{Name: "storagepoolclaims", Kind: "StoragePoolClaim", Namespaced: false}},
+			},
+		},
+	})
+}
+
+// newDynamicClient returns a fake dynamic client with the objects, which unlike NewSimpleDynamicClient
+// supports JSON merge patches of unstructured objects
+func newDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	tracker := clienttesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	for _, object := range objects {
+		if err := tracker.Add(object); err != nil {
+			panic(err)
+		}
+	}
+	objectReaction := clienttesting.ObjectReaction(tracker)
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme)
+	client.PrependReactor("*", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(clienttesting.PatchAction)
+		if !ok {
+			return objectReaction(action)
+		}
+		object, err := tracker.Get(action.GetResource(), action.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		var patch map[string]interface{}
+		if err = json.Unmarshal(patchAction.GetPatch(), &patch); err != nil {
+			return true, nil, err
+		}
+		patched := object.(*unstructured.Unstructured).DeepCopy()
+		mergePatch(patched.Object, patch)
+		return true, patched, tracker.Update(action.GetResource(), patched, action.GetNamespace())
+	})
+	return client
+}
+
+// mergePatch applies JSON merge patch (RFC 7386) to object
+func mergePatch(object, patch map[string]interface{}) {
+	for key, value := range patch {
+		patchMap, isMap := value.(map[string]interface{})
+		objectMap, wasMap := object[key].(map[string]interface{})
+		switch {
+		case value == nil:
+			delete(object, key)
+		case isMap && wasMap:
+			mergePatch(objectMap, patchMap)
+		default:
+			object[key] = value
+		}
+	}
+}
+
+func TestApplyDocuments(t *testing.T) {
+	present := &unstructured.Unstructured{}
+	present.SetAPIVersion("v1")
+	present.SetKind("ConfigMap")
+	present.SetNamespace("default")
+	present.SetName("settings")
+	present.SetResourceVersion("1")
+	present.SetLabels(map[string]string{common.RunIDLabel: "older-run", "app": "settings"})
+	client := newDynamicClient(present)
+
+	documents, err := splitManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("splitManifest() returned error: %+v", err)
+	}
+	k8s := K8S{RunID: "test-run"}.WithTracker()
+	manifests := &manifestClient{k8s: k8s, mapper: testRESTMapper(), client: client}
+	documents, err = manifests.applyDocuments(documents)
+
+	applyErr, ok := err.(*ApplyError)
+	if !ok {
+		t.Fatalf("expected *ApplyError, got: %+v", err)
+	}
+	if applyErr.Total != 5 || len(applyErr.Failed) != 1 || !strings.Contains(applyErr.Failed[0].String(), "Unknown") {
+		t.Errorf("expected only the object of unknown kind to fail, got: %v", applyErr)
+	}
+
+	expectedCreated := []bool{false, true, false, true, true}
+	for i, document := range documents {
+		if document.Err != nil {
+			continue
+		}
+		if document.Created != expectedCreated[i] {
+			t.Errorf("%s created is %t, expected %t", document, document.Created, expectedCreated[i])
+		}
+		if document.Created && document.Object.GetLabels()[common.RunIDLabel] != "test-run" {
+			t.Errorf("%s is not labelled with the run ID, labels: %v", document, document.Object.GetLabels())
+		}
+	}
+
+	spc, err := client.Resource(schema.GroupVersionResource{Group: "openebs.io", Version: "v1alpha1", Resource: "storagepoolclaims"}).
+		Get("cstor-pool", meta_v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("StoragePoolClaim is not created: %+v", err)
+	}
+	if spc.GetNamespace() != "" {
+		t.Errorf("cluster scoped StoragePoolClaim is created in namespace %q", spc.GetNamespace())
+	}
+	settings, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default").
+		Get("settings", meta_v1.GetOptions{})
+	if err != nil || settings.Object["data"] == nil {
+		t.Errorf("present ConfigMap is not updated with the data in the manifest: %v, error: %+v", settings, err)
+	}
+	// labels of the present ConfigMap which the manifest does not set are kept, and CITF ones are not relabelled
+	if labels := settings.GetLabels(); len(labels) != 2 || labels[common.RunIDLabel] != "older-run" || labels["app"] != "settings" {
+		t.Errorf("present ConfigMap is relabelled, labels: %v", labels)
+	}
+
+	expectedTracked := []TrackedObject{{Kind: KindStoragePoolClaim, Name: "cstor-pool"}}
+	if tracked := k8s.Tracker.Objects(); len(tracked) != 1 || tracked[0] != expectedTracked[0] {
+		t.Errorf("tracked objects are %v, expected %v", tracked, expectedTracked)
+	}
+}
+
+func TestApplyKeepsServerSetFields(t *testing.T) {
+	present := &unstructured.Unstructured{}
+	present.SetAPIVersion("v1")
+	present.SetKind("Service")
+	present.SetNamespace("default")
+	present.SetName("web")
+	present.SetResourceVersion("1")
+	unstructured.SetNestedField(present.Object, "10.0.0.10", "spec", "clusterIP")
+	unstructured.SetNestedField(present.Object, "None", "spec", "sessionAffinity")
+	client := newDynamicClient(present)
+
+	documents, err := splitManifest([]byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  sessionAffinity: ClientIP
+`))
+	if err != nil {
+		t.Fatalf("splitManifest() returned error: %+v", err)
+	}
+	manifests := &manifestClient{k8s: K8S{RunID: "test-run"}, mapper: testRESTMapper(), client: client}
+	if _, err = manifests.applyDocuments(documents); err != nil {
+		t.Fatalf("applyDocuments() returned error: %+v", err)
+	}
+
+	web, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "services"}).Namespace("default").
+		Get("web", meta_v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting the Service: %+v", err)
+	}
+	if clusterIP, _, _ := unstructured.NestedString(web.Object, "spec", "clusterIP"); clusterIP != "10.0.0.10" {
+		t.Errorf("clusterIP set by the api server is not kept, got: %q", clusterIP)
+	}
+	if affinity, _, _ := unstructured.NestedString(web.Object, "spec", "sessionAffinity"); affinity != "ClientIP" {
+		t.Errorf("sessionAffinity is not patched as in the manifest, got: %q", affinity)
+	}
+	if labels := web.GetLabels(); len(labels) != 1 || labels["app"] != "web" {
+		t.Errorf("labels are not patched as in the manifest, got: %v", labels)
+	}
+}
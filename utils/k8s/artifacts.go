@@ -0,0 +1,272 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArtifactOptions tells CollectArtifacts what to collect and where to put it
+type ArtifactOptions struct {
+	// Dir is the directory in which the artifact directory (or tarball) is created, temporary directory if blank
+	Dir string
+	// Namespaces are the namespaces of which pod logs, pods, PVCs and events are collected
+	Namespaces []string
+	// Tarball makes CollectArtifacts write a gzipped tarball instead of leaving a directory
+	Tarball bool
+}
+
+// artifactCollector writes the artifacts of one collection in root and remembers what could not be collected
+type artifactCollector struct {
+	k8s      K8S
+	root     string
+	failures []string
+}
+
+// fail records that the artifact at path could not be collected
+func (collector *artifactCollector) fail(path string, err error) {
+	collector.failures = append(collector.failures, fmt.Sprintf("%s: %v", path, err))
+}
+
+// writeYAML writes the object supplied (or the error in getting it) as YAML at path relative to root
+func (collector *artifactCollector) writeYAML(path string, object interface{}, err error) {
+	if err != nil {
+		collector.fail(path, err)
+		return
+	}
+	yamlBytes, err := yaml.Marshal(object)
+	if err != nil {
+		collector.fail(path, err)
+		return
+	}
+	collector.writeFile(path, yamlBytes)
+}
+
+// writeFile writes data at path relative to root, creating the directories as needed
+func (collector *artifactCollector) writeFile(path string, data []byte) {
+	fullPath := filepath.Join(collector.root, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		collector.fail(path, err)
+		return
+	}
+	if err := ioutil.WriteFile(fullPath, data, 0644); err != nil {
+		collector.fail(path, err)
+	}
+}
+
+// collectClusterObjects dumps cluster wide objects i.e. PVs, StorageClasses and all the OpenEBS CRs
+func (collector *artifactCollector) collectClusterObjects() {
+	k8s := collector.k8s
+	opts := meta_v1.ListOptions{}
+
+	persistentVolumes, err := k8s.ListPersistentVolume(opts)
+	collector.writeYAML("cluster/persistentvolumes.yaml", persistentVolumes, err)
+	storageClasses, err := k8s.ListStorageClasses(opts)
+	collector.writeYAML("cluster/storageclasses.yaml", storageClasses, err)
+
+	openebsClient := k8s.OpenebsClientSet.OpenebsV1alpha1()
+	storagePoolClaims, err := k8s.ListStoragePoolClaims(opts)
+	collector.writeYAML("openebs/storagepoolclaims.yaml", storagePoolClaims, err)
+	cStorPools, err := k8s.ListCStorPool(opts)
+	collector.writeYAML("openebs/cstorpools.yaml", cStorPools, err)
+	storagePools, err := k8s.ListStoragePool(opts)
+	collector.writeYAML("openebs/storagepools.yaml", storagePools, err)
+	cStorVolumeReplicas, err := k8s.ListCStorVolumeReplica(meta_v1.NamespaceAll, opts)
+	collector.writeYAML("openebs/cstorvolumereplicas.yaml", cStorVolumeReplicas, err)
+	cStorVolumes, err := openebsClient.CStorVolumes(meta_v1.NamespaceAll).List(opts)
+	collector.writeYAML("openebs/cstorvolumes.yaml", cStorVolumes, err)
+	disks, err := k8s.ListDisks(opts)
+	collector.writeYAML("openebs/disks.yaml", disks, err)
+	casTemplates, err := openebsClient.CASTemplates().List(opts)
+	collector.writeYAML("openebs/castemplates.yaml", casTemplates, err)
+	runTasks, err := openebsClient.RunTasks(meta_v1.NamespaceAll).List(opts)
+	collector.writeYAML("openebs/runtasks.yaml", runTasks, err)
+}
+
+// collectNamespace dumps pods, PVCs and events of the namespace and logs of all the containers in it
+func (collector *artifactCollector) collectNamespace(namespace string) {
+	k8s := collector.k8s
+	opts := meta_v1.ListOptions{}
+	dir := filepath.Join("namespaces", namespace)
+
+	pvcs, err := k8s.ListPersistentVolumeClaim(namespace, opts)
+	collector.writeYAML(filepath.Join(dir, "persistentvolumeclaims.yaml"), pvcs, err)
+	events, err := k8s.Clientset.CoreV1().Events(namespace).List(opts)
+	collector.writeYAML(filepath.Join(dir, "events.yaml"), events, err)
+	pods, err := k8s.Clientset.CoreV1().Pods(namespace).List(opts)
+	collector.writeYAML(filepath.Join(dir, "pods.yaml"), pods, err)
+	if err != nil {
+		return
+	}
+
+	for _, pod := range pods.Items {
+		containers := append(append([]core_v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			logPath := filepath.Join(dir, "logs", pod.Name+"_"+container.Name)
+			collector.collectLog(namespace, pod.Name, container.Name, false, logPath+".log")
+			if restarted(pod, container.Name) {
+				collector.collectLog(namespace, pod.Name, container.Name, true, logPath+".previous.log")
+			}
+		}
+	}
+}
+
+// restarted tells if the container of the pod has restarted i.e. it has a previous log
+func restarted(pod core_v1.Pod, containerName string) bool {
+	statuses := append(append([]core_v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.Name == containerName {
+			return status.RestartCount != 0
+		}
+	}
+	return false
+}
+
+// collectLog writes the log (previous one if `previous` is true) of the container at path
+func (collector *artifactCollector) collectLog(namespace, podName, containerName string, previous bool, path string) {
+	logBytes, err := collector.k8s.Clientset.CoreV1().Pods(namespace).GetLogs(podName, &core_v1.PodLogOptions{
+		Container: containerName,
+		Previous:  previous,
+	}).Do().Raw()
+	if err != nil {
+		collector.fail(path, err)
+		return
+	}
+	collector.writeFile(path, logBytes)
+}
+
+// CollectArtifacts writes the state of the cluster, which is needed for post-mortem of a failure,
+// in a new timestamped directory (or gzipped tarball if `opts.Tarball`) in `opts.Dir` and returns its path.
+// It contains YAML dumps of PVs, StorageClasses and all the OpenEBS CRs and, for each of `opts.Namespaces`,
+// YAML dumps of its pods, PVCs and events along with the current and previous logs of every container.
+// Collection is best-effort: it collects whatever it can and returns a non-nil error along with the path
+// if anything could not be collected. Those failures are listed in `errors.txt` of the artifacts as well.
+func (k8s K8S) CollectArtifacts(opts ArtifactOptions) (string, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	name := "citf-artifacts-" + k8s.runID() + "-" + time.Now().UTC().Format("20060102-150405.000")
+
+	root := filepath.Join(dir, name)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("error creating artifact directory %q. Error: %+v", root, err)
+	}
+
+	collector := &artifactCollector{k8s: k8s, root: root}
+	collector.collectClusterObjects()
+	for _, namespace := range opts.Namespaces {
+		collector.collectNamespace(namespace)
+	}
+	if len(collector.failures) != 0 {
+		collector.writeFile("errors.txt", []byte(strings.Join(collector.failures, "\n")+"\n"))
+	}
+
+	path := root
+	if opts.Tarball {
+		path = root + ".tar.gz"
+		if err := writeTarball(root, path); err != nil {
+			return root, fmt.Errorf("artifacts are collected in %q but error creating tarball of it. Error: %+v", root, err)
+		}
+		k8s.Logger.LogErrorf(os.RemoveAll(root), "error removing artifact directory %q after creating its tarball", root)
+	}
+
+	k8s.Logger.Debug("artifacts collected", "path", path)
+	if len(collector.failures) != 0 {
+		return path, fmt.Errorf("failed to collect %d artifacts: %s", len(collector.failures), strings.Join(collector.failures, "; "))
+	}
+	return path, nil
+}
+
+// writeTarball writes the directory supplied as gzipped tarball at path, entries are relative to parent of the directory
+func writeTarball(dir, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	err = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(filepath.Dir(dir), filePath)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		source, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		_, err = io.Copy(tarWriter, source)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ArtifactCollectingFailHandler returns a fail handler which collects artifacts as per `opts` and then calls `fail`.
+// It is meant to be registered with ginkgo so that the state of the cluster is saved at the moment of
+// failure, before AfterSuite tears it down e.g. `RegisterFailHandler(k8s.ArtifactCollectingFailHandler(opts, Fail))`
+func (k8s K8S) ArtifactCollectingFailHandler(opts ArtifactOptions, fail func(message string, callerSkip ...int)) func(message string, callerSkip ...int) {
+	return func(message string, callerSkip ...int) {
+		path, err := k8s.CollectArtifacts(opts)
+		k8s.Logger.LogErrorf(err, "error collecting artifacts")
+		if path != "" {
+			message = message + "\nartifacts of the cluster are collected at " + path
+		}
+
+		// one more frame to skip i.e. this handler
+		skip := 1
+		if len(callerSkip) != 0 {
+			skip += callerSkip[0]
+		}
+		fail(message, skip)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	core_v1 "k8s.io/api/core/v1"
+)
+
+func TestWriteTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "citf-artifacts-test")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "artifacts")
+	collector := &artifactCollector{root: root}
+	collector.writeFile("cluster/storageclasses.yaml", []byte("items: []\n"))
+	collector.writeFile("namespaces/test/logs/pod_app.log", []byte("started\n"))
+	if len(collector.failures) != 0 {
+		t.Fatalf("unable to write artifacts: %v", collector.failures)
+	}
+
+	tarballPath := filepath.Join(dir, "artifacts.tar.gz")
+	if err = writeTarball(root, tarballPath); err != nil {
+		t.Fatalf("writeTarball returned error: %+v", err)
+	}
+
+	file, err := os.Open(tarballPath)
+	if err != nil {
+		t.Fatalf("unable to open tarball: %+v", err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("tarball is not gzipped: %+v", err)
+	}
+
+	var files []string
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		if header.Typeflag == tar.TypeReg {
+			files = append(files, header.Name)
+		}
+	}
+	sort.Strings(files)
+
+	expected := []string{"artifacts/cluster/storageclasses.yaml", "artifacts/namespaces/test/logs/pod_app.log"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("files in tarball are %v, expected %v", files, expected)
+	}
+}
+
+func TestRestarted(t *testing.T) {
+	pod := core_v1.Pod{Status: core_v1.PodStatus{
+		InitContainerStatuses: []core_v1.ContainerStatus{{Name: "init", RestartCount: 2}},
+		ContainerStatuses:     []core_v1.ContainerStatus{{Name: "app"}},
+	}}
+
+	if !restarted(pod, "init") {
+		t.Errorf("init container has restarted, but restarted returned false")
+	}
+	if restarted(pod, "app") {
+		t.Errorf("app container has not restarted, but restarted returned true")
+	}
+	if restarted(pod, "unknown") {
+		t.Errorf("unknown container has no status, but restarted returned true")
+	}
+}
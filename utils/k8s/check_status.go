@@ -65,3 +65,13 @@ func (k8s K8S) IsPodRunning(pod *api_core_v1.Pod) bool {
 	}
 	return true
 }
+
+// IsNodeReady returns whether the NodeReady condition of the supplied node is true
+func (k8s K8S) IsNodeReady(node api_core_v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == api_core_v1.NodeReady {
+			return condition.Status == api_core_v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// DeleteManifestOptions tell DeleteManifest how to delete the objects and how long to wait for them to be gone
+type DeleteManifestOptions struct {
+	// PropagationPolicy is how the dependents of the objects are deleted e.g. meta_v1.DeletePropagationForeground,
+	// blank means the default of each resource
+	PropagationPolicy meta_v1.DeletionPropagation
+	// Timeout is how long to wait for all the objects to be gone, zero means not to wait i.e. to return right after
+	// requesting the deletions, objects which are still present then are not reported as stuck
+	Timeout time.Duration
+}
+
+// StuckObject is an object of a manifest which is still present when the deadline of its deletion passed
+type StuckObject struct {
+	Document AppliedDocument
+	// Finalizers are the finalizers which were holding the object, empty if it had none
+	Finalizers []string
+}
+
+func (object StuckObject) String() string {
+	if len(object.Finalizers) == 0 {
+		return object.Document.String()
+	}
+	return fmt.Sprintf("%s with finalizers %q", object.Document, object.Finalizers)
+}
+
+// DeleteError is returned by DeleteManifest when some objects could not be deleted or are not gone by the deadline
+type DeleteError struct {
+	// Failed are the documents which could not be deleted, e.g. of a kind which is not served by the cluster
+	Failed []AppliedDocument
+	// Stuck are the objects which are deleted but still present at the deadline
+	Stuck []StuckObject
+	// Timeout is how long the objects were waited for
+	Timeout time.Duration
+}
+
+func (err *DeleteError) Error() string {
+	var problems []string
+	for _, document := range err.Failed {
+		problems = append(problems, fmt.Sprintf("%s: %v", document, document.Err))
+	}
+	if len(err.Stuck) != 0 {
+		stuck := make([]string, 0, len(err.Stuck))
+		for _, object := range err.Stuck {
+			stuck = append(stuck, object.String())
+		}
+		problems = append(problems, fmt.Sprintf("still present after %v: %s", err.Timeout, strings.Join(stuck, ", ")))
+	}
+	return fmt.Sprintf("failed deleting %d objects: %s", len(err.Failed)+len(err.Stuck), strings.Join(problems, "; "))
+}
+
+// pendingDeletion is an object which is deleted and is being waited for
+type pendingDeletion struct {
+	index    int
+	resource dynamic.ResourceInterface
+}
+
+// deleteDocuments deletes the objects of the documents in reverse order, i.e. dependents before the objects
+// they depend on, and then waits for all of them to be gone for at most `opts.Timeout`.
+func (client *manifestClient) deleteDocuments(documents []AppliedDocument, opts DeleteManifestOptions) ([]AppliedDocument, error) {
+	deleteOptions := &meta_v1.DeleteOptions{}
+	if opts.PropagationPolicy != "" {
+		policy := opts.PropagationPolicy
+		deleteOptions.PropagationPolicy = &policy
+	}
+
+	deleteErr := &DeleteError{Timeout: opts.Timeout}
+	var pending []pendingDeletion
+	for i := len(documents) - 1; i >= 0; i-- {
+		document := &documents[i]
+		if document.Err == nil {
+			resource, err := client.resourceFor(document.Object)
+			if err != nil {
+				document.Err = err
+			} else if err = resource.Delete(document.Object.GetName(), deleteOptions); err != nil && !k8serrors.IsNotFound(err) {
+				document.Err = fmt.Errorf("error deleting. Error: %+v", err)
+			} else {
+				pending = append(pending, pendingDeletion{index: i, resource: resource})
+			}
+		}
+		if document.Err != nil {
+			client.k8s.Logger.Error("failed deleting", "document", document.String(), "error", document.Err)
+			deleteErr.Failed = append(deleteErr.Failed, *document)
+		}
+	}
+
+	// without timeout it does not wait, objects which are still being deleted are not stuck and stay tracked
+	if opts.Timeout > 0 {
+		err := client.k8s.pollFor(opts.Timeout, func(context.Context) (bool, string, error) {
+			var stillPresent []pendingDeletion
+			for _, deletion := range pending {
+				document := &documents[deletion.index]
+				_, err := deletion.resource.Get(document.Object.GetName(), meta_v1.GetOptions{})
+				if k8serrors.IsNotFound(err) {
+					client.k8s.forget(document.Object.GetKind(), document.Object.GetNamespace(), document.Object.GetName())
+					client.k8s.Logger.Debug("deleted", "document", document.String())
+					continue
+				}
+				stillPresent = append(stillPresent, deletion)
+			}
+			pending = stillPresent
+			return len(pending) == 0, fmt.Sprintf("%d objects still present", len(pending)), nil
+		})
+		if err != nil {
+			for _, deletion := range pending {
+				document := &documents[deletion.index]
+				stuck := StuckObject{Document: *document}
+				present, err := deletion.resource.Get(document.Object.GetName(), meta_v1.GetOptions{})
+				if err != nil {
+					document.Err = fmt.Errorf("still present after %v, error getting it: %v", opts.Timeout, err)
+				} else {
+					stuck.Finalizers = present.GetFinalizers()
+					document.Err = fmt.Errorf("still present after %v with finalizers %q", opts.Timeout, stuck.Finalizers)
+				}
+				stuck.Document.Err = document.Err
+				client.k8s.Logger.Warn("deleted object is still present", "document", document.String(), "finalizers", stuck.Finalizers)
+				deleteErr.Stuck = append(deleteErr.Stuck, stuck)
+			}
+		}
+	}
+
+	if len(deleteErr.Failed) != 0 || len(deleteErr.Stuck) != 0 {
+		return documents, deleteErr
+	}
+	return documents, nil
+}
+
+// DeleteManifest deletes every object of the multi-document YAML (or JSON) manifest, i.e. the counterpart of
+// ApplyManifest, with the propagation policy of `opts`. Objects are deleted in reverse order of the manifest,
+// so that dependents go before the objects they depend on, and then it blocks until all of them are gone or
+// `opts.Timeout` has passed. Objects which are not present are considered deleted.
+// It returns every object with the result of deleting it, and *DeleteError if any of them could not be
+// deleted or is still present at the deadline, along with the finalizers which were holding it.
+func (k8s K8S) DeleteManifest(manifest []byte, opts DeleteManifestOptions) ([]AppliedDocument, error) {
+	documents, err := splitManifest(manifest)
+	if err != nil {
+		return documents, err
+	}
+	client, err := k8s.newManifestClient()
+	if err != nil {
+		return documents, err
+	}
+	return client.deleteDocuments(documents, opts)
+}
+
+// DeleteManifestReader is same as DeleteManifest except that the manifest is read from reader
+func (k8s K8S) DeleteManifestReader(reader io.Reader, opts DeleteManifestOptions) ([]AppliedDocument, error) {
+	manifest, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest. Error: %+v", err)
+	}
+	return k8s.DeleteManifest(manifest, opts)
+}
+
+// YAMLDelete deletes the objects of the yaml specified by the argument and waits for them to be gone, see DeleteManifest.
+//    :param str yamlPath: Path of the yaml file which objects are to be deleted.
+//    :param DeleteManifestOptions opts: Propagation policy and how long to wait for the objects to be gone.
+//    :return: []AppliedDocument: every object of the file along with the result of deleting it
+//             error: *DeleteError if any object could not be deleted or is still present, otherwise `nil`
+func (k8s K8S) YAMLDelete(yamlPath string, opts DeleteManifestOptions) ([]AppliedDocument, error) {
+	manifest, err := ioutil.ReadFile(yamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q. Error: %+v", yamlPath, err)
+	}
+	return k8s.DeleteManifest(manifest, opts)
+}
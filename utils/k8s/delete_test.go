@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/CITF/utils/wait"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDeleteDocuments(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("default")
+	configMap.SetName("settings")
+	storagePoolClaim := &unstructured.Unstructured{}
+	storagePoolClaim.SetAPIVersion("openebs.io/v1alpha1")
+	storagePoolClaim.SetKind("StoragePoolClaim")
+	storagePoolClaim.SetName("cstor-pool")
+	storagePoolClaim.SetFinalizers([]string{"openebs.io/pool-protection"})
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap, storagePoolClaim)
+
+	// StoragePoolClaim is held by its finalizer i.e. deleting it does not remove it
+	client.PrependReactor("delete", "storagepoolclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	documents, err := splitManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("splitManifest() returned error: %+v", err)
+	}
+	k8s := K8S{WaitOptions: wait.Options{Interval: 10 * time.Millisecond}}.WithTracker()
+	k8s.Tracker.Track(KindStoragePoolClaim, "", "cstor-pool")
+	manifests := &manifestClient{k8s: k8s, mapper: testRESTMapper(), client: client}
+	documents, err = manifests.deleteDocuments(documents, DeleteManifestOptions{
+		PropagationPolicy: meta_v1.DeletePropagationForeground,
+		Timeout:           50 * time.Millisecond,
+	})
+
+	deleteErr, ok := err.(*DeleteError)
+	if !ok {
+		t.Fatalf("expected *DeleteError, got: %+v", err)
+	}
+	if len(deleteErr.Failed) != 1 || !strings.Contains(deleteErr.Failed[0].String(), "Unknown") {
+		t.Errorf("expected only the object of unknown kind to fail, got: %v", deleteErr.Failed)
+	}
+	if len(deleteErr.Stuck) != 1 || deleteErr.Stuck[0].Document.Object.GetName() != "cstor-pool" ||
+		!reflect.DeepEqual(deleteErr.Stuck[0].Finalizers, []string{"openebs.io/pool-protection"}) {
+		t.Errorf("expected StoragePoolClaim to be stuck with its finalizer, got: %v", deleteErr.Stuck)
+	}
+	if !strings.Contains(err.Error(), `StoragePoolClaim cstor-pool) with finalizers ["openebs.io/pool-protection"]`) {
+		t.Errorf("error message %q does not tell the finalizers of the stuck object", err.Error())
+	}
+
+	_, err = client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default").
+		Get("settings", meta_v1.GetOptions{})
+	if err == nil {
+		t.Errorf("ConfigMap is not deleted")
+	}
+	for _, document := range documents {
+		if document.Object != nil && document.Object.GetKind() == "ConfigMap" && document.Err != nil {
+			t.Errorf("%s is expected to be deleted (or not present), got error: %+v", document, document.Err)
+		}
+	}
+	if tracked := k8s.Tracker.Objects(); len(tracked) != 1 {
+		t.Errorf("stuck StoragePoolClaim is expected to be still tracked, tracked objects: %v", tracked)
+	}
+}
+
+func TestDeleteDocumentsWithoutTimeout(t *testing.T) {
+	storagePoolClaim := &unstructured.Unstructured{}
+	storagePoolClaim.SetAPIVersion("openebs.io/v1alpha1")
+	storagePoolClaim.SetKind("StoragePoolClaim")
+	storagePoolClaim.SetName("cstor-pool")
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), storagePoolClaim)
+	// StoragePoolClaim is being deleted gracefully i.e. it is still present right after deleting it
+	client.PrependReactor("delete", "storagepoolclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	documents, err := splitManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("splitManifest() returned error: %+v", err)
+	}
+	manifests := &manifestClient{k8s: K8S{}, mapper: testRESTMapper(), client: client}
+	_, err = manifests.deleteDocuments(documents, DeleteManifestOptions{})
+
+	deleteErr, ok := err.(*DeleteError)
+	if !ok {
+		t.Fatalf("expected *DeleteError for the object of unknown kind, got: %+v", err)
+	}
+	if len(deleteErr.Stuck) != 0 {
+		t.Errorf("expected no stuck objects without timeout, got: %v", deleteErr.Stuck)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openebs/CITF/common"
+	"github.com/openebs/CITF/config"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// garbageCollectedKinds are the kinds which are searched for the objects left by previous runs of CITF
+var garbageCollectedKinds = []string{
+	KindDeployment,
+	KindDaemonSet,
+	KindPersistentVolumeClaim,
+	KindNamespace,
+	KindStorageClass,
+	KindCStorVolume,
+	KindCStorVolumeReplica,
+	KindStoragePool,
+	KindCStorPool,
+	KindStoragePoolClaim,
+}
+
+// runID returns the ID of the CITF run of k8s, that of package level configuration if it is not set
+func (k8s K8S) runID() string {
+	if k8s.RunID != "" {
+		return k8s.RunID
+	}
+	return config.RunID()
+}
+
+// stamp labels the object which is about to be created with the run ID of k8s and current time,
+// so that it can be found (and garbage collected) even if this run is killed. Labels of the object are
+// replaced by a copy, so the map it had (e.g. shared by the objects made from a template) is not modified.
+func (k8s K8S) stamp(object meta_v1.Object) {
+	labels := make(map[string]string, len(object.GetLabels())+2)
+	for key, value := range object.GetLabels() {
+		labels[key] = value
+	}
+	labels[common.RunIDLabel] = k8s.runID()
+	labels[common.CreatedAtLabel] = strconv.FormatInt(time.Now().Unix(), 10)
+	object.SetLabels(labels)
+}
+
+// createdAt returns the time the object was created by CITF as per its label,
+// creation timestamp of the object if the label is absent or malformed.
+func createdAt(object meta_v1.Object) time.Time {
+	if unixSeconds, err := strconv.ParseInt(object.GetLabels()[common.CreatedAtLabel], 10, 64); err == nil {
+		return time.Unix(unixSeconds, 0)
+	}
+	return object.GetCreationTimestamp().Time
+}
+
+// listObjects returns the metadata of the objects of the kind supplied, from all the namespaces, which match opts
+func (k8s K8S) listObjects(kind string, opts meta_v1.ListOptions) ([]meta_v1.Object, error) {
+	var objects []meta_v1.Object
+	switch kind {
+	case KindDeployment:
+		list, err := k8s.ListDeployments(meta_v1.NamespaceAll, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindDaemonSet:
+		list, err := k8s.Clientset.ExtensionsV1beta1().DaemonSets(meta_v1.NamespaceAll).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindPersistentVolumeClaim:
+		list, err := k8s.ListPersistentVolumeClaim(meta_v1.NamespaceAll, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindNamespace:
+		list, err := k8s.Clientset.CoreV1().Namespaces().List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindStorageClass:
+		list, err := k8s.ListStorageClasses(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindCStorVolume:
+		list, err := k8s.OpenebsClientSet.OpenebsV1alpha1().CStorVolumes(meta_v1.NamespaceAll).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindCStorVolumeReplica:
+		list, err := k8s.ListCStorVolumeReplica(meta_v1.NamespaceAll, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindStoragePool:
+		list, err := k8s.ListStoragePool(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindCStorPool:
+		list, err := k8s.ListCStorPool(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case KindStoragePoolClaim:
+		list, err := k8s.ListStoragePoolClaims(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("listing kind %q is not supported", kind)
+	}
+	return objects, nil
+}
+
+// findObjects returns the objects of the kinds supplied which match opts and for which keep returns true.
+// Kinds which are not served (e.g. OpenEBS CRDs are not installed) are skipped.
+func (k8s K8S) findObjects(kinds []string, opts meta_v1.ListOptions, keep func(meta_v1.Object) bool) ([]TrackedObject, error) {
+	var found []TrackedObject
+	for _, kind := range kinds {
+		objects, err := k8s.listObjects(kind, opts)
+		if k8serrors.IsNotFound(err) {
+			k8s.Logger.PrintlnDebugMessage("skipping kind", kind, "it is not served:", err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s objects with labels %q. Error: %+v", kind, opts.LabelSelector, err)
+		}
+		for _, object := range objects {
+			if keep(object) {
+				found = append(found, TrackedObject{Kind: kind, Namespace: object.GetNamespace(), Name: object.GetName()})
+			}
+		}
+	}
+	return found, nil
+}
+
+// FindGarbage returns the objects which were created by runs of CITF other than the run of k8s
+// more than `olderThan` ago. Kinds which are not served (e.g. OpenEBS CRDs are not installed) are skipped.
+func (k8s K8S) FindGarbage(olderThan time.Duration) ([]TrackedObject, error) {
+	opts := meta_v1.ListOptions{
+		LabelSelector: common.RunIDLabel + "," + common.RunIDLabel + "!=" + k8s.runID(),
+	}
+	threshold := time.Now().Add(-olderThan)
+
+	return k8s.findObjects(garbageCollectedKinds, opts, func(object meta_v1.Object) bool {
+		return createdAt(object).Before(threshold)
+	})
+}
+
+// FindRunObjects returns the objects which were created by the run of k8s i.e. which are labelled with its run ID,
+// including those which are not tracked. Kinds which are not served are skipped.
+func (k8s K8S) FindRunObjects() ([]TrackedObject, error) {
+	opts := meta_v1.ListOptions{
+		LabelSelector: common.RunIDLabel + "=" + k8s.runID(),
+	}
+	return k8s.findObjects(deletionOrder, opts, func(meta_v1.Object) bool {
+		return true
+	})
+}
+
+// CollectGarbage deletes the objects left by previous runs of CITF (see FindGarbage) in reverse dependency order,
+// waiting for them to be gone for at most `timeout` in total. It returns the objects which are deleted,
+// objects which could not be deleted are reported in the returned *CleanupError.
+func (k8s K8S) CollectGarbage(olderThan, timeout time.Duration) ([]TrackedObject, error) {
+	garbage, err := k8s.FindGarbage(olderThan)
+	if err != nil {
+		return nil, err
+	}
+	return k8s.deleteObjectsAndWait(garbage, timeout)
+}
+
+// DeleteRunObjects is same as CollectGarbage except that it deletes the objects created by the run of k8s
+// (see FindRunObjects) instead of those left by previous runs, e.g. to clean up a cluster which is not torn down.
+func (k8s K8S) DeleteRunObjects(timeout time.Duration) ([]TrackedObject, error) {
+	objects, err := k8s.FindRunObjects()
+	if err != nil {
+		return nil, err
+	}
+	return k8s.deleteObjectsAndWait(objects, timeout)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openebs/CITF/common"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStamp(t *testing.T) {
+	templateLabels := map[string]string{"app": "test"}
+	pvc := &core_v1.PersistentVolumeClaim{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "pvc", Labels: templateLabels},
+	}
+	before := time.Now().Unix()
+	K8S{RunID: "run-1"}.stamp(&pvc.ObjectMeta)
+
+	if len(templateLabels) != 1 {
+		t.Errorf("labels map of the object is modified, it is: %v", templateLabels)
+	}
+
+	if pvc.Labels[common.RunIDLabel] != "run-1" {
+		t.Errorf("label %q = %q, want %q", common.RunIDLabel, pvc.Labels[common.RunIDLabel], "run-1")
+	}
+	if pvc.Labels["app"] != "test" {
+		t.Errorf("existing label is not kept, labels: %v", pvc.Labels)
+	}
+	stampedAt, err := strconv.ParseInt(pvc.Labels[common.CreatedAtLabel], 10, 64)
+	if err != nil || stampedAt < before || stampedAt > time.Now().Unix() {
+		t.Errorf("label %q = %q is not current unix time", common.CreatedAtLabel, pvc.Labels[common.CreatedAtLabel])
+	}
+}
+
+func TestCreatedAt(t *testing.T) {
+	creationTimestamp := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	labelled := &core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{
+		Labels:            map[string]string{common.CreatedAtLabel: "1500000000"},
+		CreationTimestamp: meta_v1.NewTime(creationTimestamp),
+	}}
+	if got := createdAt(labelled); !got.Equal(time.Unix(1500000000, 0)) {
+		t.Errorf("createdAt of labelled object = %v, want time of its label", got)
+	}
+
+	unlabelled := &core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{
+		Labels:            map[string]string{common.CreatedAtLabel: "not-a-time"},
+		CreationTimestamp: meta_v1.NewTime(creationTimestamp),
+	}}
+	if got := createdAt(unlabelled); !got.Equal(creationTimestamp) {
+		t.Errorf("createdAt of object with malformed label = %v, want its creation timestamp %v", got, creationTimestamp)
+	}
+}
@@ -14,18 +14,22 @@ limitations under the License.
 package k8s
 
 import (
+	"context"
 	"fmt"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/openebs/CITF/common"
 	"github.com/openebs/CITF/config"
 	openebs "github.com/openebs/CITF/pkg/client/clientset/versioned"
 	"github.com/openebs/CITF/utils/log"
 	sysutil "github.com/openebs/CITF/utils/system"
+	"github.com/openebs/CITF/utils/wait"
 	api_core_v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	// Install special auth plugins like GCP Plugins
 	// _ "k8s.io/client-go/plugin/pkg/client/auth"
 )
@@ -37,21 +41,40 @@ type K8S struct {
 	Config           *rest.Config
 	Clientset        *kubernetes.Clientset
 	OpenebsClientSet *openebs.Clientset
+	// DynamicClient works with any resource, e.g. the ones in manifests applied by YAMLApply
+	DynamicClient dynamic.Interface
+	// Tracker records the objects created through this K8S, it is nil unless enabled by WithTracker
+	Tracker *Tracker
+	// RunID is the ID of the CITF run, every object created through this K8S is labelled with it
+	RunID string
+	// Logger is the logger of the CITF instance, zero value means package level logger
+	Logger log.Logger
+	// WaitOptions tell how often the waits of this K8S check e.g. with backoff and jitter,
+	// zero value means once every second. Its Timeout is not used, each wait has its own.
+	WaitOptions wait.Options
 }
 
 func init() {
 	// check if `kubectl` is present
 	kubectlPath, err := sysutil.BinPathFromPathEnv(common.Kubectl)
-	if kubectlPath == "" {
-		// we don't want to exit here because k8s package may be used as a wrapper over client-go as well
-		glog.Errorf("%q not found in current directory or in directories represented by PATH environment variable: %+v", common.Kubectl, err)
+	// we don't want to exit here because k8s package may be used as a wrapper over client-go as well
+	if err != nil {
+		logger.Warn("unable to look for binary on path", "binary", common.Kubectl, "error", err)
+	} else if kubectlPath == "" {
+		logger.Warn("not found in current directory or in directories represented by PATH environment variable", "binary", common.Kubectl)
+	} else {
+		logger.Debug("found on path", "binary", common.Kubectl, "path", kubectlPath)
 	}
-	glog.Infof("%q found on path: %q", common.Kubectl, kubectlPath)
 }
 
 // NewK8S returns K8S struct
 func NewK8S() (K8S, error) {
-	config, err := GetClientConfig()
+	return NewK8SForConfig(config.Global())
+}
+
+// NewK8SForConfig returns K8S struct for the cluster which is configured in supplied citf Config
+func NewK8SForConfig(citfConfig *config.Config) (K8S, error) {
+	config, err := GetClientConfigForConfig(citfConfig)
 	if err != nil {
 		return K8S{}, err
 	}
@@ -66,13 +89,43 @@ func NewK8S() (K8S, error) {
 		return K8S{}, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return K8S{}, err
+	}
+
 	return K8S{
 		Config:           config,
 		Clientset:        clientset,
 		OpenebsClientSet: openebsClientSet,
+		DynamicClient:    dynamicClient,
+		RunID:            citfConfig.RunID(),
+		Logger:           citfConfig.Logger(),
 	}, nil
 }
 
+// waitOptions returns WaitOptions of k8s without timeout, waits of k8s end with their context
+func (k8s K8S) waitOptions() wait.Options {
+	opts := k8s.WaitOptions
+	opts.Timeout = 0
+	return opts
+}
+
+// pollFor polls the condition as per WaitOptions of k8s for at most `timeout`, zero means to check only once
+func (k8s K8S) pollFor(timeout time.Duration, condition wait.Condition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return wait.Poll(ctx, k8s.waitOptions(), condition)
+}
+
+// WithLogger returns a copy of k8s which logs through logger, e.g. logger of a test scoped Config.
+// Clients and tracker are shared with k8s.
+func (k8s K8S) WithLogger(logger log.Logger) K8S {
+	k8s.Logger = logger
+	return k8s
+}
+
 // Different phases of Namespace
 
 // NsGoodPhases is an array of phases of the Namespace which are considered to be good
@@ -105,21 +158,71 @@ var PodBadStates = []string{"CrashLoopBackOff", "ImagePullBackOff", "RunContaine
 // Otherwise, it tries to build config from a default kubeconfig filepath if it fails, it fallback to the default config.
 // Once it get the config, it returns the same.
 func GetClientConfig() (*rest.Config, error) {
-	// First of all I want to give `InClusterConfig` a try then we'll give `BuildConfigFromFlags` a chance to create config
-	clientConfig, err := rest.InClusterConfig()
-	if err != nil {
-		logger.PrintfDebugMessage("unable to create config: %+v\v", err)
-		err1 := err
-		clientConfig, err = clientcmd.BuildConfigFromFlags(config.KubeMasterURL(), config.KubeConfigPath())
+	return GetClientConfigForConfig(config.Global())
+}
+
+// GetClientConfigForConfig is same as GetClientConfig except that configurations are taken from
+// supplied citf Config instead of package level configurations.
+// If a context is configured, `InClusterConfig` is not tried since context can only be in kube-config.
+// Configured QPS, Burst, timeout and impersonation are applied to the config in any case.
+func GetClientConfigForConfig(citfConfig *config.Config) (*rest.Config, error) {
+	kube := citfConfig.Kube()
+
+	var clientConfig *rest.Config
+	var err error
+	if kube.Context == "" {
+		// First of all I want to give `InClusterConfig` a try then we'll give kube-config a chance to create config
+		clientConfig, err = rest.InClusterConfig()
 		if err != nil {
-			err = fmt.Errorf("InClusterConfig as well as BuildConfigFromFlags Failed. Error in InClusterConfig: %+v\nError in BuildConfigFromFlags: %+v", err1, err)
-			return nil, err
+			citfConfig.Logger().PrintfDebugMessage("unable to create in-cluster config: %+v", err)
+			err1 := err
+			clientConfig, err = buildConfigFromKubeConfig(citfConfig.KubeMasterURL(), citfConfig.KubeConfigPath(), kube.Context)
+			if err != nil {
+				err = fmt.Errorf("InClusterConfig as well as BuildConfigFromFlags Failed. Error in InClusterConfig: %+v\nError in BuildConfigFromFlags: %+v", err1, err)
+				return nil, err
+			}
+		}
+	} else {
+		clientConfig, err = buildConfigFromKubeConfig(citfConfig.KubeMasterURL(), citfConfig.KubeConfigPath(), kube.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build config for context %q. Error: %+v", kube.Context, err)
 		}
 	}
 
+	applyClientOptions(clientConfig, kube)
 	return clientConfig, nil
 }
 
+// buildConfigFromKubeConfig builds config from the kube-config at supplied path using the supplied context.
+// Blank context means current context of the kube-config, non-blank masterURL overrides the server of the cluster.
+func buildConfigFromKubeConfig(masterURL, kubeConfigPath, context string) (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfigPath},
+		&clientcmd.ConfigOverrides{
+			ClusterInfo:    clientcmdapi.Cluster{Server: masterURL},
+			CurrentContext: context,
+		}).ClientConfig()
+}
+
+// applyClientOptions sets QPS, Burst, timeout and impersonation in clientConfig which are configured i.e. non-zero
+func applyClientOptions(clientConfig *rest.Config, kube config.KubeConfiguration) {
+	if kube.QPS != 0 {
+		clientConfig.QPS = kube.QPS
+	}
+	if kube.Burst != 0 {
+		clientConfig.Burst = kube.Burst
+	}
+	if kube.Timeout != 0 {
+		clientConfig.Timeout = kube.Timeout
+	}
+	if kube.ImpersonateUser != "" {
+		clientConfig.Impersonate.UserName = kube.ImpersonateUser
+	}
+	if len(kube.ImpersonateGroups) != 0 {
+		clientConfig.Impersonate.Groups = kube.ImpersonateGroups
+	}
+}
+
 // GetClientsetFromConfig takes REST config and Create a clientset based on that and return that clientset
 func GetClientsetFromConfig(config *rest.Config) (*kubernetes.Clientset, error) {
 	clientset, err := kubernetes.NewForConfig(config)
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openebs/CITF/config"
+	"github.com/openebs/CITF/utils/wait"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: first
+  cluster:
+    server: https://first:6443
+- name: second
+  cluster:
+    server: https://second:6443
+users:
+- name: tester
+  user:
+    token: secret
+contexts:
+- name: first
+  context:
+    cluster: first
+    user: tester
+- name: second
+  context:
+    cluster: second
+    user: tester
+current-context: first
+`
+
+func TestGetClientConfigForConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "citf-k8s-test")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubeConfigPath := filepath.Join(dir, "kubeconfig")
+	if err = ioutil.WriteFile(kubeConfigPath, []byte(testKubeConfig), 0600); err != nil {
+		t.Fatalf("unable to write kube-config: %+v", err)
+	}
+
+	tests := []struct {
+		name       string
+		citfConf   string
+		wantHost   string
+		wantQPS    float32
+		wantBurst  int
+		wantTime   time.Duration
+		wantUser   string
+		wantGroups []string
+	}{
+		{
+			name:     "current context",
+			citfConf: "kube:\n  context: first\n",
+			wantHost: "https://first:6443",
+		},
+		{
+			name:       "non-default context with client options",
+			citfConf:   "kube:\n  context: second\n  qps: 50\n  burst: 100\n  timeout: 30s\n  impersonateUser: ci\n  impersonateGroups: [system:masters]\n",
+			wantHost:   "https://second:6443",
+			wantQPS:    50,
+			wantBurst:  100,
+			wantTime:   30 * time.Second,
+			wantUser:   "ci",
+			wantGroups: []string{"system:masters"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			citfConfPath := filepath.Join(dir, "citf.yaml")
+			citfConfData := "kubeConfigPath: " + kubeConfigPath + "\n" + tt.citfConf
+			if err := ioutil.WriteFile(citfConfPath, []byte(citfConfData), 0600); err != nil {
+				t.Fatalf("unable to write citf config: %+v", err)
+			}
+			citfConfig, err := config.NewConfig(citfConfPath)
+			if err != nil {
+				t.Fatalf("config.NewConfig() returned error: %+v", err)
+			}
+
+			clientConfig, err := GetClientConfigForConfig(citfConfig)
+			if err != nil {
+				t.Fatalf("GetClientConfigForConfig() returned error: %+v", err)
+			}
+			if clientConfig.Host != tt.wantHost {
+				t.Errorf("Host = %q, want %q", clientConfig.Host, tt.wantHost)
+			}
+			if clientConfig.QPS != tt.wantQPS || clientConfig.Burst != tt.wantBurst || clientConfig.Timeout != tt.wantTime {
+				t.Errorf("QPS, Burst, Timeout = %v, %v, %v, want %v, %v, %v",
+					clientConfig.QPS, clientConfig.Burst, clientConfig.Timeout, tt.wantQPS, tt.wantBurst, tt.wantTime)
+			}
+			if clientConfig.Impersonate.UserName != tt.wantUser || !reflect.DeepEqual(clientConfig.Impersonate.Groups, tt.wantGroups) {
+				t.Errorf("Impersonate = %+v, want user %q and groups %q", clientConfig.Impersonate, tt.wantUser, tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestIsQuit(t *testing.T) {
+	neverDone := func(context.Context) (bool, string, error) { return false, "", nil }
+	opts := wait.Options{Interval: time.Millisecond}
+
+	quit := make(chan bool, 1)
+	ctx, cancel := wait.QuitContext(context.Background(), quit)
+	defer cancel()
+	quit <- true
+	if err := wait.Poll(ctx, opts, neverDone); !isQuit(err) {
+		t.Errorf("expected wait ended by quit to be told apart, got: %+v", err)
+	}
+
+	opts.Timeout = 5 * time.Millisecond
+	if err := wait.PollTimeout(opts, neverDone); isQuit(err) {
+		t.Errorf("wait ended by timeout is taken for quit: %+v", err)
+	}
+}
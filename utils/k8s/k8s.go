@@ -25,10 +25,10 @@ import (
 
 	"errors"
 
-	"github.com/golang/glog"
 	"github.com/openebs/CITF/common"
 	strutil "github.com/openebs/CITF/utils/string"
 	sysutil "github.com/openebs/CITF/utils/system"
+	"github.com/openebs/CITF/utils/wait"
 	core_v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	storage_v1 "k8s.io/api/storage/v1"
@@ -85,69 +85,74 @@ func (k8s K8S) GetPods(namespace, podNamePrefix string) ([]core_v1.Pod, error) {
 	}
 
 	// Find the Pod
-	logger.PrintlnDebugMessage(strings.Repeat("*", 80))
-	logger.PrintfDebugMessage("all pods in %q namespace are:\n", namespace)
+	k8s.Logger.PrintlnDebugMessage(strings.Repeat("*", 80))
+	k8s.Logger.PrintfDebugMessage("all pods in %q namespace are:\n", namespace)
 	for _, pod := range pods.Items {
-		logger.PrintlnDebugMessage("complete Pod name is:", pod.Name)
+		k8s.Logger.PrintlnDebugMessage("complete Pod name is:", pod.Name)
 		if strings.HasPrefix(pod.Name, podNamePrefix) {
 			thePods = append(thePods, pod)
 		}
 	}
-	logger.PrintlnDebugMessage(strings.Repeat("*", 80))
+	k8s.Logger.PrintlnDebugMessage(strings.Repeat("*", 80))
 
 	return thePods, err
 }
 
+// GetPodsWithContext returns all the Pods object which has a prefix specified in its name in the given namespace.
+// it tries to get the pods which match the criteria unless `ctx` ends or it gets at least one such pod.
+// If `ctx` ends first, it returns *wait.TimeoutError which tells why the last try failed.
+// NOTE: it counts pods which are not even in ContainerCreating state yet. Deal with them properly.
+func (k8s K8S) GetPodsWithContext(ctx context.Context, namespace, podNamePrefix string) (thePods []core_v1.Pod, err error) {
+	err = wait.Poll(ctx, k8s.waitOptions(), func(context.Context) (bool, string, error) {
+		pods, err := k8s.GetPods(namespace, podNamePrefix)
+		if err != nil {
+			return false, fmt.Sprintf("error getting pods: %v", err), nil
+		}
+		if len(pods) == 0 {
+			return false, fmt.Sprintf("no pod which starts with %q in namespace %q", podNamePrefix, namespace), nil
+		}
+		thePods = pods
+		return true, "", nil
+	})
+	return thePods, err
+}
+
+// isQuit tells whether the wait ended because `true` was sent on the quit channel of wait.QuitContext
+func isQuit(err error) bool {
+	timeoutErr, ok := err.(*wait.TimeoutError)
+	return ok && timeoutErr.Cause == context.Canceled
+}
+
 // GetPodsUntilQuitSignal returns all the Pods object which has a prefix specified in its name in the given namespace.
 // it tries to get the pods which match the criteria unless `true` received from `quit` or it gets at least one such pod.
+// If it is told to quit before getting any pod it returns error.
 // NOTE: it counts pods which are not even in ContainerCreating state yet. Deal with them properly.
-func (k8s K8S) GetPodsUntilQuitSignal(namespace, podNamePrefix string, quit <-chan bool) (thePods []core_v1.Pod, err error) {
-	for {
-		select {
-		case quitting := <-quit:
-			if quitting {
-				if len(thePods) == 0 {
-					err = fmt.Errorf("failed to get any pod which starts with %q, forced to quit", podNamePrefix)
-				} else {
-					glog.Info("quit signal received `true`, quitting...")
-					err = nil
-				}
-				return
-			}
-			glog.Info("quit signal received `false`, not quitting...")
-
-		default:
-			thePods, err = k8s.GetPods(namespace, podNamePrefix)
-			logger.LogErrorf(err, "error getting pods")
-			if err == nil && len(thePods) != 0 {
-				return thePods, nil
-			}
-			// If no pods found and debug is enabled then
-			logger.PrintlnDebugMessage("no pods found in this iteration")
+func (k8s K8S) GetPodsUntilQuitSignal(namespace, podNamePrefix string, quit <-chan bool) ([]core_v1.Pod, error) {
+	ctx, cancel := wait.QuitContext(context.Background(), quit)
+	defer cancel()
 
-			time.Sleep(time.Second)
-		}
+	thePods, err := k8s.GetPodsWithContext(ctx, namespace, podNamePrefix)
+	if isQuit(err) {
+		return nil, fmt.Errorf("failed to get any pod which starts with %q, forced to quit", podNamePrefix)
 	}
+	return thePods, err
 }
 
 // GetPodsOrTimeout returns all the Pods object which has a prefix specified in its name in the given namespace.
 // it tries to get the pods which match the criteria unless timeout occurs or it gets at least one such pod.
 // NOTE: it counts pods which are not even in ContainerCreating state yet. Deal with them properly.
 func (k8s K8S) GetPodsOrTimeout(namespace, podNamePrefix string, timeout time.Duration) ([]core_v1.Pod, error) {
-	quit := make(chan bool)
-	time.AfterFunc(timeout, func() {
-		glog.Infof("timeout of duration %v ends", timeout)
-		quit <- true
-	})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	return k8s.GetPodsUntilQuitSignal(namespace, podNamePrefix, quit)
+	return k8s.GetPodsWithContext(ctx, namespace, podNamePrefix)
 }
 
 // GetPodsOrBlock returns all the Pods object which has a prefix specified in its name in the given namespace.
 // it tries to get the pods which match the criteria unless it gets at least one such pod.
 // NOTE: it counts pods which are not even in ContainerCreating state yet. Deal with them properly.
 func (k8s K8S) GetPodsOrBlock(namespace, podNamePrefix string) ([]core_v1.Pod, error) {
-	return k8s.GetPodsUntilQuitSignal(namespace, podNamePrefix, nil)
+	return k8s.GetPodsWithContext(context.Background(), namespace, podNamePrefix)
 }
 
 // ReloadPod reloads the state of the pod supplied and return error if any
@@ -186,31 +191,47 @@ func (k8s K8S) GetContainerStatesInPod(pod *core_v1.Pod) (containerStates []core
 	return
 }
 
+// GetContainerStatesInPodWithContext tries to get the states of all the containers of the supplied Pod
+// until it has at least one container state or `ctx` ends. The pod is reloaded before every try.
+//    :param ctx: context which ends the wait, *wait.TimeoutError is returned then.
+//    :param pod: pod object on which operation should be performed
+//    :return: []k8s.io/api/core/v1.ContainerState: slice which holds states of the containers.
+//           : error: error if occurred, `nil` otherwise
+func (k8s K8S) GetContainerStatesInPodWithContext(ctx context.Context, pod *core_v1.Pod) (containerStates []core_v1.ContainerState, err error) {
+	if pod == nil {
+		return nil, errors.New("nil argument supplied for pod")
+	}
+
+	err = wait.Poll(ctx, k8s.waitOptions(), func(context.Context) (bool, string, error) {
+		reloaded, err := k8s.ReloadPod(pod)
+		if err != nil {
+			return false, fmt.Sprintf("error reloading pod %q of namespace %q: %v", pod.Name, pod.Namespace, err), nil
+		}
+		pod = reloaded
+		containerStates, _ = k8s.GetContainerStatesInPod(pod)
+		if len(containerStates) == 0 {
+			return false, fmt.Sprintf("pod %q of namespace %q has no container states yet, phase: %s", pod.Name, pod.Namespace, k8s.GetPodPhase(pod)), nil
+		}
+		return true, "", nil
+	})
+	return
+}
+
 // GetContainerStatesInPodUntilToldToQuit tries to get the states of all the containers of the supplied Pod
-// until `true` is sent in `quit` channel
+// until `true` is sent in `quit` channel. Being told to quit is not an error, states are empty then.
 //    :param pod: pod object on which operation should be performed
 //    :param quit: channel which is used to stop this function.
 //    :return: []k8s.io/api/core/v1.ContainerState: slice which holds states of the containers.
 //           : error: error if occurred, `nil` otherwise
-func (k8s K8S) GetContainerStatesInPodUntilToldToQuit(pod *core_v1.Pod, quit <-chan bool) (containerStates []core_v1.ContainerState, err error) {
-	for {
-		select {
-		case quitting := <-quit:
-			if quitting {
-				return
-			}
-		default:
-			pod, err = k8s.ReloadPod(pod)
-			if err != nil {
-				continue
-			}
-			containerStates, err = k8s.GetContainerStatesInPod(pod)
-			if err != nil || len(containerStates) == 0 {
-				continue
-			}
-			return
-		}
+func (k8s K8S) GetContainerStatesInPodUntilToldToQuit(pod *core_v1.Pod, quit <-chan bool) ([]core_v1.ContainerState, error) {
+	ctx, cancel := wait.QuitContext(context.Background(), quit)
+	defer cancel()
+
+	containerStates, err := k8s.GetContainerStatesInPodWithContext(ctx, pod)
+	if isQuit(err) {
+		return containerStates, nil
 	}
+	return containerStates, err
 }
 
 // GetContainerStatesInPodWithTimeout returns the states of all the containers of the supplied Pod.
@@ -218,21 +239,11 @@ func (k8s K8S) GetContainerStatesInPodUntilToldToQuit(pod *core_v1.Pod, quit <-c
 //    :param timeout: maximum time duration to get the container's state.
 //    :return: []k8s.io/api/core/v1.ContainerState: slice which holds states of the containers.
 //           : error: error if occurred, `nil` otherwise
-func (k8s K8S) GetContainerStatesInPodWithTimeout(pod *core_v1.Pod, timeout time.Duration) (containerStates []core_v1.ContainerState, err error) {
-	quit := make(chan bool)
-	done := make(chan bool)
-
-	go func() {
-		containerStates, err = k8s.GetContainerStatesInPodUntilToldToQuit(pod, quit)
-		done <- true
-	}()
+func (k8s K8S) GetContainerStatesInPodWithTimeout(pod *core_v1.Pod, timeout time.Duration) ([]core_v1.ContainerState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	select {
-	case <-time.After(timeout):
-		quit <- true
-	case <-done:
-	}
-	return
+	return k8s.GetContainerStatesInPodWithContext(ctx, pod)
 }
 
 // GetContainerStateByIndexInPod tries to get the state of the container of supplied index of the supplied Pod only once
@@ -257,7 +268,7 @@ func (k8s K8S) GetContainerStateByIndexInPod(pod *core_v1.Pod, containerIndex in
 	// if that pod has no containers
 	if len(containerStates) == 0 {
 		err = fmt.Errorf("no containers found in pod %q of namespace %q", pod.Name, pod.Namespace)
-	} else if len(containerStates) < containerIndex { // if required number of container is not present
+	} else if len(containerStates) <= containerIndex { // if required number of container is not present
 		err = fmt.Errorf("pod %q of namespace %q has only %d container(s) but expecting %d containers", pod.Name, pod.Namespace, len(containerStates), containerIndex+1)
 		// inside this block expected number of containers (i. e. containerIndex+1) are always more than one
 		// because control will enter this block only when number of containers is 1 or more than one
@@ -270,29 +281,57 @@ func (k8s K8S) GetContainerStateByIndexInPod(pod *core_v1.Pod, containerIndex in
 	return
 }
 
+// GetContainerStateByIndexInPodWithContext tries to get the state of the container of supplied index of the supplied Pod
+// until the container has a state or `ctx` ends. The pod is reloaded before every try.
+//    :param ctx: context which ends the wait, *wait.TimeoutError is returned then.
+//    :param pod: pod object on which operation should be performed
+//    :param containerIndex: index of the container for which you want state.
+//    :return: k8s.io/api/core/v1.ContainerState: state of the container.
+//           : error: error if occurred, `nil` otherwise
+func (k8s K8S) GetContainerStateByIndexInPodWithContext(ctx context.Context, pod *core_v1.Pod, containerIndex int) (containerState core_v1.ContainerState, err error) {
+	// If we get negative index or no pod then trying again will not help
+	if containerIndex < 0 {
+		return containerState, errors.New(negativeIndexErrorMessage)
+	}
+	if pod == nil {
+		return containerState, errors.New("nil argument supplied for pod")
+	}
+
+	err = wait.Poll(ctx, k8s.waitOptions(), func(context.Context) (bool, string, error) {
+		reloaded, err := k8s.ReloadPod(pod)
+		if err != nil {
+			return false, fmt.Sprintf("error reloading pod %q of namespace %q: %v", pod.Name, pod.Namespace, err), nil
+		}
+		pod = reloaded
+		state, err := k8s.GetContainerStateByIndexInPod(pod, containerIndex)
+		if err != nil {
+			return false, err.Error(), nil
+		}
+		if reflect.DeepEqual(state, core_v1.ContainerState{}) {
+			return false, fmt.Sprintf("container %d of pod %q of namespace %q has no state yet", containerIndex, pod.Name, pod.Namespace), nil
+		}
+		containerState = state
+		return true, "", nil
+	})
+	return
+}
+
 // GetContainerStateByIndexInPodUntilToldToQuit tries to get the state of the container of supplied index of the supplied Pod
-// until `true` is sent in `quit` channel
+// until `true` is sent in `quit` channel. Being told to quit is not an error, state is empty then.
 //    :param pod: pod object on which operation should be performed
 //    :param containerIndex: index of the container for which you want state.
 //    :param quit: channel which is used to stop this function.
 //    :return: k8s.io/api/core/v1.ContainerState: state of the container.
 //           : error: error if occurred, `nil` otherwise
-func (k8s K8S) GetContainerStateByIndexInPodUntilToldToQuit(pod *core_v1.Pod, containerIndex int, quit <-chan bool) (containerState core_v1.ContainerState, err error) {
-	for {
-		select {
-		case quitting := <-quit:
-			if quitting {
-				return
-			}
-		default:
-			containerState, err = k8s.GetContainerStateByIndexInPod(pod, containerIndex)
-			// If we get negative index then we should immediately return otherwise try again
-			if err.Error() != negativeIndexErrorMessage && (err != nil || reflect.DeepEqual(containerState, core_v1.ContainerState{})) {
-				continue
-			}
-			return
-		}
+func (k8s K8S) GetContainerStateByIndexInPodUntilToldToQuit(pod *core_v1.Pod, containerIndex int, quit <-chan bool) (core_v1.ContainerState, error) {
+	ctx, cancel := wait.QuitContext(context.Background(), quit)
+	defer cancel()
+
+	containerState, err := k8s.GetContainerStateByIndexInPodWithContext(ctx, pod, containerIndex)
+	if isQuit(err) {
+		return containerState, nil
 	}
+	return containerState, err
 }
 
 // GetContainerStateByIndexInPodWithTimeout returns the state of the container of supplied index of the supplied Pod.
@@ -301,21 +340,11 @@ func (k8s K8S) GetContainerStateByIndexInPodUntilToldToQuit(pod *core_v1.Pod, co
 //    :param timeout: maximum time duration to get the container's state.
 //    :return: k8s.io/api/core/v1.ContainerState: state of the container.
 //           : error: error if occurred, `nil` otherwise
-func (k8s K8S) GetContainerStateByIndexInPodWithTimeout(pod *core_v1.Pod, containerIndex int, timeout time.Duration) (containerState core_v1.ContainerState, err error) {
-	quit := make(chan bool)
-	done := make(chan bool)
-
-	go func() {
-		containerState, err = k8s.GetContainerStateByIndexInPodUntilToldToQuit(pod, containerIndex, quit)
-		done <- true
-	}()
+func (k8s K8S) GetContainerStateByIndexInPodWithTimeout(pod *core_v1.Pod, containerIndex int, timeout time.Duration) (core_v1.ContainerState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	select {
-	case <-time.After(timeout):
-		quit <- true
-	case <-done:
-	}
-	return
+	return k8s.GetContainerStateByIndexInPodWithContext(ctx, pod, containerIndex)
 }
 
 // GetNodes returns a list of all the nodes.
@@ -323,19 +352,20 @@ func (k8s K8S) GetContainerStateByIndexInPodWithTimeout(pod *core_v1.Pod, contai
 func (k8s K8S) GetNodes() (nodeNames []core_v1.Node, err error) {
 	nodeNames = []core_v1.Node{}
 
-	// To handle latency it tries 10 times each after 1 second of wait
-	waited := 0
-	for waited < 10 {
+	// To handle latency it tries for 10 seconds while the list is empty
+	waitErr := k8s.pollFor(10*time.Second, func(context.Context) (bool, string, error) {
 		nodeList, err := k8s.Clientset.CoreV1().Nodes().List(meta_v1.ListOptions{})
 		if err != nil {
-			break
-		} else if len(nodeList.Items) == 0 {
-			time.Sleep(time.Second)
-			waited++
-			continue
+			return false, "", err
+		}
+		if len(nodeList.Items) == 0 {
+			return false, "no nodes found", nil
 		}
 		nodeNames = nodeList.Items
-		break
+		return true, "", nil
+	})
+	if waitErr != nil && !wait.IsTimeout(waitErr) {
+		k8s.Logger.Error("error listing nodes", "error", waitErr)
 	}
 
 	return
@@ -357,6 +387,56 @@ func (k8s K8S) GetNodeNames() (nodeNames []string, err error) {
 	return
 }
 
+// CheckClusterReady checks whether the api server answers and all the nodes of the cluster are Ready.
+// It returns nil if cluster is ready, otherwise error describing why it is not.
+func (k8s K8S) CheckClusterReady() error {
+	version, err := k8s.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("api server is not reachable. Error: %+v", err)
+	}
+	k8s.Logger.PrintfDebugMessage("api server is reachable, version: %s", version.GitVersion)
+
+	nodeList, err := k8s.Clientset.CoreV1().Nodes().List(meta_v1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes. Error: %+v", err)
+	}
+	if len(nodeList.Items) == 0 {
+		return fmt.Errorf("cluster has no nodes")
+	}
+	for _, node := range nodeList.Items {
+		if !k8s.IsNodeReady(node) {
+			return fmt.Errorf("node %q is not ready", node.Name)
+		}
+	}
+	return nil
+}
+
+// WaitUntilClusterIsReadyWithContext blocks until the api server answers and all the nodes are Ready.
+// it checks as per WaitOptions of k8s and returns *wait.TimeoutError, which tells why cluster was not ready
+// at the last check, if `ctx` ends before cluster is ready.
+func (k8s K8S) WaitUntilClusterIsReadyWithContext(ctx context.Context) error {
+	return wait.Poll(ctx, k8s.waitOptions(), func(context.Context) (bool, string, error) {
+		if err := k8s.CheckClusterReady(); err != nil {
+			k8s.Logger.PrintfDebugMessage("cluster is not ready yet: %+v", err)
+			return false, err.Error(), nil
+		}
+		return true, "", nil
+	})
+}
+
+// WaitUntilClusterIsReadyOrTimeout blocks until the api server answers and all the nodes are Ready.
+// it checks once every `interval` and returns the last error found if cluster is not ready within `timeout`.
+func (k8s K8S) WaitUntilClusterIsReadyOrTimeout(timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	k8s.WaitOptions.Interval = interval
+	if err := k8s.WaitUntilClusterIsReadyWithContext(ctx); err != nil {
+		return fmt.Errorf("cluster is not ready after %v. Error: %+v", timeout, err)
+	}
+	return nil
+}
+
 // TODO: Write a function to label the node
 // LabelNode label the node with the given key and value.
 //    :param string node_name: Name of the node.
@@ -380,11 +460,13 @@ func (k8s K8S) ApplyDSFromManifestStruct(manifest v1beta1.DaemonSet) (v1beta1.Da
 	if manifest.Namespace == "" {
 		manifest.Namespace = core_v1.NamespaceDefault
 	}
+	k8s.stamp(&manifest.ObjectMeta)
 	daemonsetClient := k8s.Clientset.ExtensionsV1beta1().DaemonSets(manifest.Namespace)
 	ds, err := daemonsetClient.Create(&manifest)
 	if err != nil {
 		return v1beta1.DaemonSet{}, err
 	}
+	k8s.track(KindDaemonSet, ds.Namespace, ds.Name)
 	return *ds, nil
 }
 
@@ -406,24 +488,6 @@ func (k8s K8S) GetDaemonsetStructFromYamlBytes(yamlBytes []byte) (v1beta1.Daemon
 	return ds, nil
 }
 
-// TODO: Write a function to apply the YAML with the help of client-go
-// YAMLApply apply the yaml specified by the argument.
-//    :param str yamlPath: Path of the yaml file that is to be applied.
-// func YAMLApplyAPI(yamlPath string) error { return fmt.Errorf("Not Implemented") }
-
-// YAMLApply apply the yaml specified by the argument.
-//    :param str yamlPath: Path of the yaml file that is to be applied.
-func (k8s K8S) YAMLApply(yamlPath string) error {
-	// TODO: Try using API call first. i.e. Using client-go
-
-	err := sysutil.RunCommand(common.Kubectl + " apply -f " + yamlPath)
-	logger.LogErrorf(err, "error occurred while applying the %s", yamlPath)
-	if err != nil {
-		return fmt.Errorf("failed applying %s", yamlPath)
-	}
-	return nil
-}
-
 // ExecToPodThroughAPI performs non-interactive exec to the pod with the specified command using client-go.
 // :param string command: list of the str which specify the command.
 // :param string containerName: name of the container in the Pod. (If the Pod has only one container, then it can be Empty String)
@@ -462,7 +526,7 @@ func (k8s K8S) ExecToPodThroughAPI(command, containerName, podName, namespace st
 
 	req.VersionedParams(&podExecOptions, parameterCodec)
 
-	logger.PrintlnDebugMessage("Request URL:", req.URL().String())
+	k8s.Logger.PrintlnDebugMessage("Request URL:", req.URL().String())
 
 	exec, err := remotecommand.NewSPDYExecutor(k8s.Config, "POST", req.URL())
 	if err != nil {
@@ -527,7 +591,7 @@ func (k8s K8S) ExecToPod(command, containerName, podName, namespace string) (str
 	}
 
 	// When Exec through API fails
-	glog.Errorf("error while exec into Pod through API. Stderr: %q. Error: %+v", stderr, err)
+	k8s.Logger.Error("error while exec into Pod through API", "stderr", stderr, "error", err)
 	return k8s.ExecToPodThroughKubectl(command, containerName, podName, namespace)
 }
 
@@ -555,106 +619,95 @@ func (k8s K8S) GetLog(podName, namespace string) (string, error) {
 	}
 
 	buf.ReadFrom(readCloser)
-	logger.PrintlnDebugMessage("Log of Pod", podName, "in Namespace", namespace, "through API:")
-	logger.PrintlnDebugMessage(buf.String())
+	k8s.Logger.PrintlnDebugMessage("Log of Pod", podName, "in Namespace", namespace, "through API:")
+	k8s.Logger.PrintlnDebugMessage(buf.String())
 
 	return buf.String(), nil
 
 use_kubectl:
-	glog.Errorf("Error while getting log with API call. Error: %+v", err)
+	k8s.Logger.Error("error while getting log with API call", "pod", podName, "namespace", namespace, "error", err)
 
 	return sysutil.ExecCommand(common.Kubectl + " -n " + namespace + " logs " + podName)
 }
 
-// BlockUntilPodIsUp blocks until all containers of the given pod is ready
-// or when `true` is send to channel `quit`. It returns error if occurred.
-func (k8s K8S) BlockUntilPodIsUp(pod *core_v1.Pod, quit <-chan bool) (err error) {
-	var containerStates []core_v1.ContainerState
-	var terminatedContainers int
-
-	for {
-		select {
-		case quitting := <-quit:
-			if quitting {
-				glog.Info("forced to quit")
-				return
-			}
-		default:
-			if pod, err = k8s.ReloadPod(pod); err != nil {
-				err = fmt.Errorf("error in reloading pod: %+v", err)
-				goto continue_loop
-			}
+// podIsUp is the condition of BlockUntilPodIsUpWithContext, it reloads the pod and tells whether
+// all of its containers are running. It returns error if the pod can never be up.
+func (k8s K8S) podIsUp(pod *core_v1.Pod) wait.Condition {
+	return func(context.Context) (bool, string, error) {
+		reloaded, err := k8s.ReloadPod(pod)
+		if err != nil {
+			return false, fmt.Sprintf("error in reloading pod: %+v", err), nil
+		}
+		pod = reloaded
 
-			containerStates, err = k8s.GetContainerStatesInPodUntilToldToQuit(pod, nil)
-			logger.LogError(err, "error getting container states")
+		containerStates, _ := k8s.GetContainerStatesInPod(pod)
+		if len(containerStates) == 0 {
+			return false, fmt.Sprintf("pod %q of namespace %q has no container states yet, phase: %s", pod.Name, pod.Namespace, k8s.GetPodPhase(pod)), nil
+		}
 
-			// count terminated containers
-			terminatedContainers = 0
-			for _, containerState := range containerStates {
-				if containerState.Terminated != nil {
-					terminatedContainers++
-				}
-			}
-			// if all containers are terminated return error
-			if terminatedContainers == len(containerStates) {
-				return fmt.Errorf("all containers in the pod %q of namespace %q have terminated", pod.Name, pod.Namespace)
+		// count terminated containers
+		terminatedContainers := 0
+		for _, containerState := range containerStates {
+			if containerState.Terminated != nil {
+				terminatedContainers++
 			}
+		}
+		// if all containers are terminated return error
+		if terminatedContainers == len(containerStates) {
+			return false, "", fmt.Errorf("all containers in the pod %q of namespace %q have terminated", pod.Name, pod.Namespace)
+		}
 
-			// if any container is in waiting state
-			for _, containerState := range containerStates {
-				if containerState.Waiting != nil {
-					if k8s.IsPodStateWait(containerState.Waiting.Reason) {
-						fmt.Printf("waiting because pod-state: %q. Details: %+v\n", containerState.Waiting.Reason, *containerState.Waiting)
-						goto continue_loop
-					} else if !k8s.IsPodStateGood(containerState.Waiting.Reason) {
-						return fmt.Errorf("pod %q of namespace %q is in bad state: %q. Details: %+v", pod.Name, pod.Namespace, containerState.Waiting.Reason, *containerState.Waiting)
-					}
+		// if any container is in waiting state
+		for _, containerState := range containerStates {
+			if containerState.Waiting != nil {
+				if k8s.IsPodStateWait(containerState.Waiting.Reason) {
+					k8s.Logger.Info("waiting for pod", "pod", pod.Name, "namespace", pod.Namespace, "state", containerState.Waiting.Reason, "message", containerState.Waiting.Message)
+					return false, fmt.Sprintf("pod %q of namespace %q is in state %q", pod.Name, pod.Namespace, containerState.Waiting.Reason), nil
+				} else if !k8s.IsPodStateGood(containerState.Waiting.Reason) {
+					return false, "", fmt.Errorf("pod %q of namespace %q is in bad state: %q. Details: %+v", pod.Name, pod.Namespace, containerState.Waiting.Reason, *containerState.Waiting)
 				}
 			}
+		}
 
-			for _, containerState := range containerStates {
-				if containerState.Running == nil {
-					// At this point all states are None,
-					// so just showing phase is enough
-					fmt.Printf("Waiting because pod %q of namespace %q is in phase: %q\n", pod.Name, pod.Namespace, k8s.GetPodPhase(pod))
-					goto continue_loop
-				}
+		for _, containerState := range containerStates {
+			if containerState.Running == nil {
+				// At this point all states are None,
+				// so just showing phase is enough
+				k8s.Logger.Info("waiting for pod", "pod", pod.Name, "namespace", pod.Namespace, "phase", k8s.GetPodPhase(pod))
+				return false, fmt.Sprintf("pod %q of namespace %q is in phase %q", pod.Name, pod.Namespace, k8s.GetPodPhase(pod)), nil
 			}
-
-			goto break_loop
 		}
-
-	break_loop:
-		break
-	continue_loop:
-		time.Sleep(time.Second)
-		continue
+		return true, "", nil
 	}
-
-	return nil
 }
 
 // BlockUntilPodIsUpWithContext blocks until all containers of the given pod is ready
-// or when supplied context cancelled. It returns error if occurred.
-func (k8s K8S) BlockUntilPodIsUpWithContext(ctx context.Context, pod *core_v1.Pod) (err error) {
-	quit := make(chan bool)
-	done := make(chan error)
-	go func() {
-		done <- k8s.BlockUntilPodIsUp(pod, quit)
-	}()
+// or when supplied context ends. It returns error if occurred, *wait.TimeoutError if context ended first.
+func (k8s K8S) BlockUntilPodIsUpWithContext(ctx context.Context, pod *core_v1.Pod) error {
+	if pod == nil {
+		return errors.New("nil argument supplied for pod")
+	}
+	return wait.Poll(ctx, k8s.waitOptions(), k8s.podIsUp(pod))
+}
+
+// BlockUntilPodIsUp blocks until all containers of the given pod is ready
+// or when `true` is send to channel `quit`. It returns error if occurred, being told to quit is not an error.
+func (k8s K8S) BlockUntilPodIsUp(pod *core_v1.Pod, quit <-chan bool) error {
+	ctx, cancel := wait.QuitContext(context.Background(), quit)
+	defer cancel()
 
-	select {
-	case err := <-done:
-		return err
-	case <-ctx.Done():
-		quit <- true
-		return fmt.Errorf("context cancelled while waiting for pod %q of namespace %q to be up", pod.Name, pod.Namespace)
+	err := k8s.BlockUntilPodIsUpWithContext(ctx, pod)
+	if isQuit(err) {
+		k8s.Logger.Info("forced to quit")
+		return nil
 	}
+	return err
 }
 
 // BlockUntilPodIsUpOrTimeout blocks until all containers of the given pod is ready
 // or when timeout is hit. It returns error if occurred.
-// It uses `BlockUntilPodIsUpWithContext` internally, so in case of timeout it will give error describing "context cancelled"
+// It uses `BlockUntilPodIsUpWithContext` internally, so in case of timeout it gives *wait.TimeoutError
+// which tells the state of the pod at the last check.
 func (k8s K8S) BlockUntilPodIsUpOrTimeout(pod *core_v1.Pod, timeout time.Duration) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -664,8 +717,15 @@ func (k8s K8S) BlockUntilPodIsUpOrTimeout(pod *core_v1.Pod, timeout time.Duratio
 
 // CreateDeployment creates the Deployment in the given namespace.
 func (k8s K8S) CreateDeployment(namespace string, deployment *v1beta1.Deployment) (*v1beta1.Deployment, error) {
+	// a copy is labelled, so that deployment of the caller is not modified
+	stamped := *deployment
+	k8s.stamp(&stamped.ObjectMeta)
 	deploymentClient := k8s.Clientset.ExtensionsV1beta1().Deployments(namespace)
-	return deploymentClient.Create(deployment);
+	createdDeployment, err := deploymentClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindDeployment, createdDeployment.Namespace, createdDeployment.Name)
+	}
+	return createdDeployment, err
 }
 
 // GetDeployment returns the Deployment object for given deploymentName in the given namespace.
@@ -688,8 +748,14 @@ func (k8s K8S) DeleteDeployment(namespace, deploymentName string, opts *meta_v1.
 
 // CreateStorageClass creates the StorageClass.
 func (k8s K8S) CreateStorgeClass(storageClass *storage_v1.StorageClass) (*storage_v1.StorageClass, error) {
+	stamped := *storageClass
+	k8s.stamp(&stamped.ObjectMeta)
 	storageClassClient := k8s.Clientset.StorageV1().StorageClasses()
-	return storageClassClient.Create(storageClass)
+	createdStorageClass, err := storageClassClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindStorageClass, "", createdStorageClass.Name)
+	}
+	return createdStorageClass, err
 }
 
 // GetStorageClass returns the StorageClass object for given storageClassName.
@@ -712,8 +778,14 @@ func (k8s K8S) DeleteStorageClass(storageClassName string, opts *meta_v1.DeleteO
 
 // CreatePersistentVolumeClaim creates the PVC in the given namespace.
 func (k8s K8S) CreatePersistentVolumeClaim(namespace string, persistentVolumeClaim *core_v1.PersistentVolumeClaim) (*core_v1.PersistentVolumeClaim, error) {
+	stamped := *persistentVolumeClaim
+	k8s.stamp(&stamped.ObjectMeta)
 	persistentVolumeClaimClient := k8s.Clientset.CoreV1().PersistentVolumeClaims(namespace)
-	return persistentVolumeClaimClient.Create(persistentVolumeClaim)
+	createdPersistentVolumeClaim, err := persistentVolumeClaimClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindPersistentVolumeClaim, createdPersistentVolumeClaim.Namespace, createdPersistentVolumeClaim.Name)
+	}
+	return createdPersistentVolumeClaim, err
 }
 
 // ListPersistentVolumeClaim lists all the PVCs in the given namespace.
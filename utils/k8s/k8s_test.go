@@ -0,0 +1,47 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetContainerStateByIndexInPod(t *testing.T) {
+	running := core_v1.ContainerState{Running: &core_v1.ContainerStateRunning{}}
+	pod := &core_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Status: core_v1.PodStatus{
+			ContainerStatuses: []core_v1.ContainerStatus{{Name: "nginx", State: running}},
+		},
+	}
+
+	state, err := K8S{}.GetContainerStateByIndexInPod(pod, 0)
+	if err != nil || state.Running == nil {
+		t.Errorf("GetContainerStateByIndexInPod(pod, 0) = %+v, %v, expected running state", state, err)
+	}
+
+	// index equal to the number of containers is out of range
+	_, err = K8S{}.GetContainerStateByIndexInPod(pod, 1)
+	if err == nil || !strings.Contains(err.Error(), "has only 1 container(s) but expecting 2 containers") {
+		t.Errorf("expected error for container index out of range, got: %v", err)
+	}
+
+	if _, err = (K8S{}).GetContainerStateByIndexInPod(pod, -1); err == nil || err.Error() != negativeIndexErrorMessage {
+		t.Errorf("expected error for negative index, got: %v", err)
+	}
+}
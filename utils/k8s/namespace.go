@@ -0,0 +1,154 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openebs/CITF/common"
+	core_v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNamespace is a namespace created for a single test by CreateTestNamespace.
+// Name should be used by the test instead of hard-coding "default", so tests do not interfere with each other.
+type TestNamespace struct {
+	Name string
+	k8s  K8S
+}
+
+// maxGenerateNameLength is the maximum length of GenerateName of a namespace, so that it is still a DNS label
+// after kubernetes appends its random suffix of 5 characters
+const maxGenerateNameLength = 63 - 5
+
+// invalidNameCharacters are the runs of characters which can not be in a DNS label
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// namespaceGenerateName returns the prefix of the name of the namespace, kubernetes appends a random suffix to it.
+// Prefix can be anything e.g. t.Name() like "TestFoo/sub_case", characters which can not be in a namespace name
+// are replaced by "-" and it is shortened to fit in the length of a namespace name.
+func namespaceGenerateName(prefix string) string {
+	prefix = invalidNameCharacters.ReplaceAllString(strings.ToLower(prefix), "-")
+	prefix = strings.Trim(prefix, "-")
+	if prefix == "" {
+		return "citf-"
+	}
+	if maxLength := maxGenerateNameLength - len("citf--"); len(prefix) > maxLength {
+		prefix = strings.TrimRight(prefix[:maxLength], "-")
+	}
+	return "citf-" + prefix + "-"
+}
+
+// CreateTestNamespace creates a uniquely named namespace which name starts with `citf-<prefix>-`,
+// labelled like every object created through CITF, and waits until it is in good phase (see IsNSinGoodPhase)
+// for at most `timeout`. Returned TestNamespace should be deleted by the test, preferably by a defer.
+func (k8s K8S) CreateTestNamespace(prefix string, timeout time.Duration) (*TestNamespace, error) {
+	namespace := &core_v1.Namespace{
+		ObjectMeta: meta_v1.ObjectMeta{GenerateName: namespaceGenerateName(prefix)},
+	}
+	k8s.stamp(&namespace.ObjectMeta)
+
+	namespace, err := k8s.Clientset.CoreV1().Namespaces().Create(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error creating namespace with prefix %q. Error: %+v", prefix, err)
+	}
+	k8s.track(KindNamespace, "", namespace.Name)
+	testNamespace := &TestNamespace{Name: namespace.Name, k8s: k8s}
+
+	err = k8s.pollFor(timeout, func(context.Context) (bool, string, error) {
+		namespace, err := k8s.Clientset.CoreV1().Namespaces().Get(testNamespace.Name, meta_v1.GetOptions{})
+		if err != nil {
+			return false, "", fmt.Errorf("error getting namespace %q. Error: %+v", testNamespace.Name, err)
+		}
+		return k8s.IsNSinGoodPhase(*namespace), fmt.Sprintf("namespace %q is in phase %q", namespace.Name, namespace.Status.Phase), nil
+	})
+	if err != nil {
+		return testNamespace, err
+	}
+	k8s.Logger.Debug("created namespace", "namespace", testNamespace.Name)
+	return testNamespace, nil
+}
+
+// Delete deletes the namespace and waits until it is gone i.e. it has left the Terminating phase,
+// for at most `timeout`. Deleting a namespace which is already gone is not an error.
+func (ns *TestNamespace) Delete(timeout time.Duration) error {
+	namespacesClient := ns.k8s.Clientset.CoreV1().Namespaces()
+	err := namespacesClient.Delete(ns.Name, &meta_v1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting namespace %q. Error: %+v", ns.Name, err)
+	}
+
+	err = ns.k8s.pollFor(timeout, func(context.Context) (bool, string, error) {
+		namespace, err := namespacesClient.Get(ns.Name, meta_v1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return true, "", nil
+		}
+		if err != nil {
+			return false, "", fmt.Errorf("error getting namespace %q. Error: %+v", ns.Name, err)
+		}
+		return false, fmt.Sprintf("namespace %q is still in phase %q", ns.Name, namespace.Status.Phase), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if ns.k8s.Tracker != nil {
+		ns.k8s.Tracker.forget(TrackedObject{Kind: KindNamespace, Name: ns.Name})
+	}
+	ns.k8s.Logger.Debug("deleted namespace", "namespace", ns.Name)
+	return nil
+}
+
+// WithTestNamespace creates a namespace by CreateTestNamespace, runs `test` with its name and deletes it.
+// Namespace is deleted even if `test` panics, in that case the panic continues after the deletion.
+// `timeout` applies to the creation and the deletion separately.
+func (k8s K8S) WithTestNamespace(prefix string, timeout time.Duration, test func(namespace string)) (err error) {
+	ns, err := k8s.CreateTestNamespace(prefix, timeout)
+	if ns != nil {
+		defer func() {
+			deleteErr := ns.Delete(timeout)
+			k8s.Logger.LogErrorf(deleteErr, "error cleaning up namespace %q", ns.Name)
+			if err == nil {
+				err = deleteErr
+			}
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	test(ns.Name)
+	return nil
+}
+
+// RunNamespaces returns the names of the namespaces created by the CITF run of k8s i.e. labelled with its run ID
+func (k8s K8S) RunNamespaces() ([]string, error) {
+	namespaces, err := k8s.Clientset.CoreV1().Namespaces().List(meta_v1.ListOptions{
+		LabelSelector: common.RunIDLabel + "=" + k8s.runID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaceGenerateName(t *testing.T) {
+	tests := map[string]string{
+		"":                              "citf-",
+		"replication":                   "citf-replication-",
+		"Replication-":                  "citf-replication-",
+		"-":                             "citf-",
+		"TestFoo/sub_case":              "citf-testfoo-sub-case-",
+		"TestPool/cStor pool (3 disks)": "citf-testpool-cstor-pool-3-disks-",
+		strings.Repeat("a", 100):        "citf-" + strings.Repeat("a", 52) + "-",
+		strings.Repeat("a", 51) + "/b":  "citf-" + strings.Repeat("a", 51) + "-",
+	}
+	for prefix, expected := range tests {
+		if got := namespaceGenerateName(prefix); got != expected {
+			t.Errorf("namespaceGenerateName(%q) = %q, want %q", prefix, got, expected)
+		}
+		if got := namespaceGenerateName(prefix); len(got)+5 > 63 {
+			t.Errorf("namespaceGenerateName(%q) = %q is too long for a namespace name with random suffix", prefix, got)
+		}
+	}
+}
@@ -20,8 +20,15 @@ import (
 
 // CreateStoragePoolClaim takes StoragePoolClaim as an argument and creates it.
 func (k8s K8S) CreateStoragePoolClaim(storagePoolClaim *openebs_v1.StoragePoolClaim) (*openebs_v1.StoragePoolClaim, error) {
+	// a copy is labelled, so that storagePoolClaim of the caller is not modified
+	stamped := *storagePoolClaim
+	k8s.stamp(&stamped.ObjectMeta)
 	spcClient := k8s.OpenebsClientSet.OpenebsV1alpha1().StoragePoolClaims()
-	return spcClient.Create(storagePoolClaim)
+	createdStoragePoolClaim, err := spcClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindStoragePoolClaim, "", createdStoragePoolClaim.Name)
+	}
+	return createdStoragePoolClaim, err
 }
 
 // GetStoragePoolClaim returns the StoragePoolClaim object for given spcName.
@@ -44,8 +51,14 @@ func (k8s K8S) DeleteStoragePoolClaim(spcName string, opts *meta_v1.DeleteOption
 
 // CreateCStorPool creates the CStorPool and returns it.
 func (k8s K8S) CreateCStorPool(cStorPool *openebs_v1.CStorPool) (*openebs_v1.CStorPool, error) {
+	stamped := *cStorPool
+	k8s.stamp(&stamped.ObjectMeta)
 	cStorePoolClient := k8s.OpenebsClientSet.OpenebsV1alpha1().CStorPools()
-	return cStorePoolClient.Create(cStorPool)
+	createdCStorPool, err := cStorePoolClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindCStorPool, "", createdCStorPool.Name)
+	}
+	return createdCStorPool, err
 }
 
 // GetCStorPool returns the CStorPool object for given cStorPoolName.
@@ -68,8 +81,14 @@ func (k8s K8S) DeleteCStorPool(cStorPoolName string, opts *meta_v1.DeleteOptions
 
 // CreateStoragePool takes the representation of a StoragePool and creates it.
 func (k8s K8S) CreateStoragePool(storagePool *openebs_v1.StoragePool) (*openebs_v1.StoragePool, error) {
+	stamped := *storagePool
+	k8s.stamp(&stamped.ObjectMeta)
 	storagePoolClient := k8s.OpenebsClientSet.OpenebsV1alpha1().StoragePools()
-	return storagePoolClient.Create(storagePool)
+	createdStoragePool, err := storagePoolClient.Create(&stamped)
+	if err == nil {
+		k8s.track(KindStoragePool, "", createdStoragePool.Name)
+	}
+	return createdStoragePool, err
 }
 
 // GetStoragePool returns the StoragePool object for the given storagePoolName.
@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/openebs/CITF/config"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// maxNameLength is the maximum length of the names made by `suffixed`, i.e. that of a DNS label
+	maxNameLength = 63
+	// maxNameSuffixLength is the maximum length of TemplateOptions.NameSuffix, so that names keep most of their length
+	maxNameSuffixLength = 20
+)
+
+// nameSuffixPattern is what TemplateOptions.NameSuffix must match, i.e. a DNS label
+var nameSuffixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// TemplateOptions tell how a manifest template is rendered
+type TemplateOptions struct {
+	// Values are the values of the template i.e. `.` in it. Every value used by the template must be present.
+	Values map[string]interface{}
+	// NameSuffix is what helper `suffixed` appends to the names, lowercase alphanumeric characters or "-"
+	// (not at either end) of length up to 20. Blank means a suffix derived from the run ID,
+	// so the names are same in every render of the run (e.g. to apply and later delete) but differ between runs.
+	NameSuffix string
+}
+
+// nameSuffixForRun returns the suffix of names for the run ID, i.e. its last 8 lowercase alphanumeric characters
+func nameSuffixForRun(runID string) string {
+	var suffix []rune
+	for _, r := range strings.ToLower(runID) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			suffix = append(suffix, r)
+		}
+	}
+	if len(suffix) > 8 {
+		suffix = suffix[len(suffix)-8:]
+	}
+	if len(suffix) == 0 {
+		return "citf"
+	}
+	return string(suffix)
+}
+
+// suffixedName returns name followed by "-" and suffix, name is shortened if the result would be too long for a name
+func suffixedName(name, suffix string) string {
+	if len(name)+1+len(suffix) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength-1-len(suffix)], "-.")
+	}
+	return name + "-" + suffix
+}
+
+// quantityOperation returns a template function which applies operation on the quantities supplied as strings
+func quantityOperation(operation func(a, b *resource.Quantity)) func(a, b string) (string, error) {
+	return func(a, b string) (string, error) {
+		quantityA, err := resource.ParseQuantity(a)
+		if err != nil {
+			return "", fmt.Errorf("invalid quantity %q. Error: %+v", a, err)
+		}
+		quantityB, err := resource.ParseQuantity(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid quantity %q. Error: %+v", b, err)
+		}
+		operation(&quantityA, &quantityB)
+		return quantityA.String(), nil
+	}
+}
+
+// mulQuantity returns the quantity supplied as string multiplied by factor, in the format of the quantity.
+// Large products (e.g. "1Ei" by 16) do not overflow.
+func mulQuantity(quantity string, factor int64) (string, error) {
+	parsed, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return "", fmt.Errorf("invalid quantity %q. Error: %+v", quantity, err)
+	}
+	negative := factor < 0
+	if negative {
+		factor = -factor
+	}
+	// product is the sum of parsed doubled once for every bit of factor; Add switches to decimals on overflow
+	product := resource.Quantity{Format: parsed.Format}
+	for addend := parsed.DeepCopy(); factor != 0; factor >>= 1 {
+		if factor&1 == 1 {
+			product.Add(addend)
+		}
+		addend.Add(addend.DeepCopy())
+	}
+	if negative {
+		product.Neg()
+	}
+	return product.String(), nil
+}
+
+// templateFuncs returns the helper functions of manifest templates
+func templateFuncs(nameSuffix string) template.FuncMap {
+	return template.FuncMap{
+		// suffixed returns the name with the suffix of the render e.g. `{{ suffixed "nginx" }}` is "nginx-1a2b3c4d"
+		"suffixed": func(name string) string {
+			return suffixedName(name, nameSuffix)
+		},
+		// addQuantity and subQuantity e.g. `{{ addQuantity "1Gi" "512Mi" }}` is "1536Mi"
+		"addQuantity": quantityOperation(func(a, b *resource.Quantity) { a.Add(*b) }),
+		"subQuantity": quantityOperation(func(a, b *resource.Quantity) { a.Sub(*b) }),
+		// mulQuantity e.g. `{{ mulQuantity "1Gi" 3 }}` is "3Gi"
+		"mulQuantity": mulQuantity,
+		"b64enc": func(str string) string {
+			return base64.StdEncoding.EncodeToString([]byte(str))
+		},
+		"b64dec": func(str string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(str)
+			return string(decoded), err
+		},
+	}
+}
+
+// RenderManifest renders the manifest template (Go text/template) with `opts.Values` and returns the manifest.
+// It fails if the template uses a value which is not present. Besides the functions of text/template,
+// the template can use `suffixed`, `addQuantity`, `subQuantity`, `mulQuantity`, `b64enc` and `b64dec`.
+// It does not talk to any cluster, so tests can compare the output with a snapshot (with fixed `opts.NameSuffix`).
+func RenderManifest(name, text string, opts TemplateOptions) ([]byte, error) {
+	nameSuffix := opts.NameSuffix
+	if nameSuffix == "" {
+		nameSuffix = nameSuffixForRun(config.RunID())
+	} else if len(nameSuffix) > maxNameSuffixLength || !nameSuffixPattern.MatchString(nameSuffix) {
+		return nil, fmt.Errorf("invalid name suffix %q, it must be a DNS label of up to %d characters", nameSuffix, maxNameSuffixLength)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs(nameSuffix)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %q. Error: %+v", name, err)
+	}
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, opts.Values); err != nil {
+		return nil, fmt.Errorf("error rendering template %q. Error: %+v", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderManifestFile is same as RenderManifest except that the template is read from the file which path is supplied
+func RenderManifestFile(templatePath string, opts TemplateOptions) ([]byte, error) {
+	text, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q. Error: %+v", templatePath, err)
+	}
+	return RenderManifest(filepath.Base(templatePath), string(text), opts)
+}
+
+// withRunSuffix returns opts with the name suffix derived from the run ID of k8s, if it is not set
+func (k8s K8S) withRunSuffix(opts TemplateOptions) TemplateOptions {
+	if opts.NameSuffix == "" {
+		opts.NameSuffix = nameSuffixForRun(k8s.runID())
+	}
+	return opts
+}
+
+// YAMLApplyTemplate renders the manifest template which path is supplied, see RenderManifest,
+// and applies the rendered manifest, see ApplyManifest.
+func (k8s K8S) YAMLApplyTemplate(templatePath string, opts TemplateOptions) ([]AppliedDocument, error) {
+	manifest, err := RenderManifestFile(templatePath, k8s.withRunSuffix(opts))
+	if err != nil {
+		return nil, err
+	}
+	return k8s.ApplyManifest(manifest)
+}
+
+// YAMLDeleteTemplate renders the manifest template which path is supplied, see RenderManifest,
+// and deletes the objects of the rendered manifest, see DeleteManifest.
+// Same `templateOpts` as in YAMLApplyTemplate delete the objects applied by it.
+func (k8s K8S) YAMLDeleteTemplate(templatePath string, templateOpts TemplateOptions, deleteOpts DeleteManifestOptions) ([]AppliedDocument, error) {
+	manifest, err := RenderManifestFile(templatePath, k8s.withRunSuffix(templateOpts))
+	if err != nil {
+		return nil, err
+	}
+	return k8s.DeleteManifest(manifest, deleteOpts)
+}
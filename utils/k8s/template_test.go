@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTemplate = `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{ suffixed .name }}
+  namespace: {{ .namespace }}
+spec:
+  storageClassName: {{ .storageClass }}
+  resources:
+    requests:
+      storage: {{ mulQuantity .size 3 }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ suffixed .name }}
+data:
+  password: {{ b64enc .password }}
+`
+
+func TestRenderManifest(t *testing.T) {
+	values := map[string]interface{}{
+		"name":         "data",
+		"namespace":    "citf-test",
+		"storageClass": "openebs-cstor",
+		"size":         "1Gi",
+		"password":     "secret",
+	}
+	rendered, err := RenderManifest("pvc", testTemplate, TemplateOptions{Values: values, NameSuffix: "snap"})
+	if err != nil {
+		t.Fatalf("RenderManifest() returned error: %+v", err)
+	}
+
+	expected := `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-snap
+  namespace: citf-test
+spec:
+  storageClassName: openebs-cstor
+  resources:
+    requests:
+      storage: 3Gi
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: data-snap
+data:
+  password: c2VjcmV0
+`
+	if string(rendered) != expected {
+		t.Errorf("RenderManifest() rendered:\n%s\nexpected:\n%s", rendered, expected)
+	}
+
+	delete(values, "storageClass")
+	_, err = RenderManifest("pvc", testTemplate, TemplateOptions{Values: values})
+	if err == nil || !strings.Contains(err.Error(), "storageClass") {
+		t.Errorf("expected error mentioning the missing value, got: %+v", err)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: `{{ addQuantity "1Gi" "512Mi" }}`, expected: "1536Mi"},
+		{text: `{{ subQuantity "2Gi" "1Gi" }}`, expected: "1Gi"},
+		{text: `{{ mulQuantity "100m" 3 }}`, expected: "300m"},
+		{text: `{{ mulQuantity "1Ei" 16 }}`, expected: "16Ei"},
+		{text: `{{ mulQuantity "1Gi" 0 }}`, expected: "0"},
+		{text: `{{ mulQuantity "500Mi" -2 }}`, expected: "-1000Mi"},
+		{text: `{{ b64dec "c2VjcmV0" }}`, expected: "secret"},
+		{text: `{{ suffixed "pool" }}`, expected: "pool-1a2b"},
+		{text: `{{ suffixed "` + strings.Repeat("a", 60) + `" }}`, expected: strings.Repeat("a", 58) + "-1a2b"},
+	}
+	for _, tt := range tests {
+		rendered, err := RenderManifest("funcs", tt.text, TemplateOptions{NameSuffix: "1a2b"})
+		if err != nil {
+			t.Errorf("RenderManifest(%q) returned error: %+v", tt.text, err)
+			continue
+		}
+		if string(rendered) != tt.expected {
+			t.Errorf("RenderManifest(%q) = %q, expected %q", tt.text, rendered, tt.expected)
+		}
+	}
+
+	if _, err := RenderManifest("funcs", `{{ addQuantity "1Gi" "lots" }}`, TemplateOptions{}); err == nil {
+		t.Errorf("expected error for invalid quantity")
+	}
+
+	for _, nameSuffix := range []string{strings.Repeat("a", 62), "Run_7", "-1a2b", "1a2b-"} {
+		_, err := RenderManifest("funcs", `{{ suffixed "pool" }}`, TemplateOptions{NameSuffix: nameSuffix})
+		if err == nil || !strings.Contains(err.Error(), "invalid name suffix") {
+			t.Errorf("expected error for name suffix %q, got: %v", nameSuffix, err)
+		}
+	}
+}
+
+func TestNameSuffixForRun(t *testing.T) {
+	tests := map[string]string{
+		"20180101-150405-1a2b3c4d": "1a2b3c4d",
+		"CI_Run.7":                 "cirun7",
+		"--":                       "citf",
+	}
+	for runID, expected := range tests {
+		if suffix := nameSuffixForRun(runID); suffix != expected {
+			t.Errorf("nameSuffixForRun(%q) = %q, expected %q", runID, suffix, expected)
+		}
+	}
+}
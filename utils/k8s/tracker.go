@@ -0,0 +1,310 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openebs/CITF/utils/wait"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of the objects which are tracked by Tracker
+const (
+	KindDeployment            = "Deployment"
+	KindDaemonSet             = "DaemonSet"
+	KindPersistentVolumeClaim = "PersistentVolumeClaim"
+	KindNamespace             = "Namespace"
+	KindStorageClass          = "StorageClass"
+	KindCStorVolume           = "CStorVolume"
+	KindCStorVolumeReplica    = "CStorVolumeReplica"
+	KindStoragePool           = "StoragePool"
+	KindCStorPool             = "CStorPool"
+	KindStoragePoolClaim      = "StoragePoolClaim"
+)
+
+// deletionOrder is the order in which kinds are deleted, objects which depend on others are deleted first
+// e.g. PVC before the StorageClass it uses, StorageClass before the StoragePoolClaim it refers.
+var deletionOrder = []string{
+	KindDeployment,
+	KindDaemonSet,
+	KindPersistentVolumeClaim,
+	KindNamespace,
+	KindStorageClass,
+	KindCStorVolume,
+	KindCStorVolumeReplica,
+	KindStoragePool,
+	KindCStorPool,
+	KindStoragePoolClaim,
+}
+
+// TrackedObject identifies an object created through CITF. Namespace is blank for cluster scoped objects.
+type TrackedObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (object TrackedObject) String() string {
+	if object.Namespace == "" {
+		return object.Kind + "/" + object.Name
+	}
+	return object.Kind + "/" + object.Namespace + "/" + object.Name
+}
+
+// Tracker records every object created through K8S which has it, so that all of them can be deleted at once.
+// It is safe for concurrent use.
+type Tracker struct {
+	mutex   sync.Mutex
+	objects []TrackedObject
+}
+
+// NewTracker returns an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Track records the object supplied
+func (tracker *Tracker) Track(kind, namespace, name string) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.objects = append(tracker.objects, TrackedObject{Kind: kind, Namespace: namespace, Name: name})
+}
+
+// Objects returns the objects tracked, in the order they were created
+func (tracker *Tracker) Objects() []TrackedObject {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	return append([]TrackedObject{}, tracker.objects...)
+}
+
+// forget removes the supplied object from tracker
+func (tracker *Tracker) forget(object TrackedObject) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	for i := range tracker.objects {
+		if tracker.objects[i] == object {
+			tracker.objects = append(tracker.objects[:i], tracker.objects[i+1:]...)
+			return
+		}
+	}
+}
+
+// WithTracker returns a copy of k8s which tracks every object it creates. Tracking is opt-in.
+func (k8s K8S) WithTracker() K8S {
+	k8s.Tracker = NewTracker()
+	return k8s
+}
+
+// track records the object in the tracker of k8s, if any
+func (k8s K8S) track(kind, namespace, name string) {
+	if k8s.Tracker != nil {
+		k8s.Tracker.Track(kind, namespace, name)
+	}
+}
+
+// forget removes the object from the tracker of k8s, if any, e.g. after it is deleted
+func (k8s K8S) forget(kind, namespace, name string) {
+	if k8s.Tracker != nil {
+		k8s.Tracker.forget(TrackedObject{Kind: kind, Namespace: namespace, Name: name})
+	}
+}
+
+// deleteTrackedObject sends the delete request for the object supplied.
+// Dependents are deleted in background, even for the kinds which orphan them by default.
+func (k8s K8S) deleteTrackedObject(object TrackedObject) error {
+	propagation := meta_v1.DeletePropagationBackground
+	opts := &meta_v1.DeleteOptions{PropagationPolicy: &propagation}
+	switch object.Kind {
+	case KindDeployment:
+		return k8s.DeleteDeployment(object.Namespace, object.Name, opts)
+	case KindDaemonSet:
+		return k8s.Clientset.ExtensionsV1beta1().DaemonSets(object.Namespace).Delete(object.Name, opts)
+	case KindPersistentVolumeClaim:
+		return k8s.DeletePersistentVolumeClaim(object.Namespace, object.Name, opts)
+	case KindNamespace:
+		return k8s.Clientset.CoreV1().Namespaces().Delete(object.Name, opts)
+	case KindStorageClass:
+		return k8s.DeleteStorageClass(object.Name, opts)
+	case KindCStorVolume:
+		return k8s.OpenebsClientSet.OpenebsV1alpha1().CStorVolumes(object.Namespace).Delete(object.Name, opts)
+	case KindCStorVolumeReplica:
+		return k8s.DeleteCStorVolumeReplica(object.Name, object.Namespace, opts)
+	case KindStoragePool:
+		return k8s.DeleteStoragePool(object.Name, opts)
+	case KindCStorPool:
+		return k8s.DeleteCStorPool(object.Name, opts)
+	case KindStoragePoolClaim:
+		return k8s.DeleteStoragePoolClaim(object.Name, opts)
+	}
+	return fmt.Errorf("deleting kind %q is not supported", object.Kind)
+}
+
+// getTrackedObjectMeta returns the metadata of the object supplied
+func (k8s K8S) getTrackedObjectMeta(object TrackedObject) (meta_v1.Object, error) {
+	opts := meta_v1.GetOptions{}
+	switch object.Kind {
+	case KindDeployment:
+		return k8s.GetDeployment(object.Namespace, object.Name, opts)
+	case KindDaemonSet:
+		return k8s.Clientset.ExtensionsV1beta1().DaemonSets(object.Namespace).Get(object.Name, opts)
+	case KindPersistentVolumeClaim:
+		return k8s.GetPersistentVolumeClaim(object.Namespace, object.Name, opts)
+	case KindNamespace:
+		return k8s.Clientset.CoreV1().Namespaces().Get(object.Name, opts)
+	case KindStorageClass:
+		return k8s.GetStorageClass(object.Name, opts)
+	case KindCStorVolume:
+		return k8s.OpenebsClientSet.OpenebsV1alpha1().CStorVolumes(object.Namespace).Get(object.Name, opts)
+	case KindCStorVolumeReplica:
+		return k8s.GetCStorVolumeReplica(object.Name, object.Namespace, opts)
+	case KindStoragePool:
+		return k8s.GetStoragePool(object.Name, opts)
+	case KindCStorPool:
+		return k8s.GetCStorPool(object.Name, opts)
+	case KindStoragePoolClaim:
+		return k8s.GetStoragePoolClaim(object.Name, opts)
+	}
+	return nil, fmt.Errorf("getting kind %q is not supported", object.Kind)
+}
+
+// CleanupError is returned by DeleteTrackedObjects when some of the tracked objects could not be removed
+type CleanupError struct {
+	// Failures has the reason for every object which could not be removed
+	Failures map[TrackedObject]error
+}
+
+func (err *CleanupError) Error() string {
+	failures := make([]string, 0, len(err.Failures))
+	for object, reason := range err.Failures {
+		failures = append(failures, fmt.Sprintf("%s: %v", object, reason))
+	}
+	sort.Strings(failures)
+	return fmt.Sprintf("failed to remove %d tracked objects: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// sortForDeletion sorts the objects in the order they should be deleted i.e. as per deletionOrder
+// and, within a kind, the latest created first. Kinds which are not known are kept at the end.
+func sortForDeletion(objects []TrackedObject) []TrackedObject {
+	rank := map[string]int{}
+	for i, kind := range deletionOrder {
+		rank[kind] = i
+	}
+	kindRank := func(kind string) int {
+		if r, ok := rank[kind]; ok {
+			return r
+		}
+		return len(deletionOrder)
+	}
+
+	sorted := make([]TrackedObject, len(objects))
+	// reverse of creation order, so that stable sort keeps the latest first within a kind
+	for i, object := range objects {
+		sorted[len(objects)-1-i] = object
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return kindRank(sorted[i].Kind) < kindRank(sorted[j].Kind)
+	})
+	return sorted
+}
+
+// DeleteTrackedObjects deletes every object tracked by the tracker of k8s in reverse dependency order.
+// For every kind, it waits until its objects are actually gone (i.e. their finalizers have run)
+// before deleting the next kind, for at most `timeout` in total.
+// Objects which are removed are forgotten by the tracker; objects which could not be removed
+// are reported in the returned *CleanupError and are still tracked, so that it can be tried again.
+func (k8s K8S) DeleteTrackedObjects(timeout time.Duration) error {
+	if k8s.Tracker == nil {
+		return nil
+	}
+
+	_, err := k8s.deleteObjectsAndWait(k8s.Tracker.Objects(), timeout)
+	return err
+}
+
+// deleteObjectsAndWait deletes the objects supplied in the order of sortForDeletion, waiting for every kind
+// to be gone before deleting the next one, for at most `timeout` in total. It returns the objects which are removed,
+// those are forgotten by the tracker of k8s if any. Objects which could not be removed are reported in *CleanupError.
+func (k8s K8S) deleteObjectsAndWait(objects []TrackedObject, timeout time.Duration) ([]TrackedObject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	failures := map[TrackedObject]error{}
+	var deleted []TrackedObject
+
+	objects = sortForDeletion(objects)
+	for start := 0; start < len(objects); {
+		// objects of same kind are deleted together, then waited for
+		end := start
+		for end < len(objects) && objects[end].Kind == objects[start].Kind {
+			end++
+		}
+
+		var pending []TrackedObject
+		for _, object := range objects[start:end] {
+			err := k8s.deleteTrackedObject(object)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				failures[object] = err
+				continue
+			}
+			pending = append(pending, object)
+		}
+
+		err := wait.Poll(ctx, k8s.waitOptions(), func(context.Context) (bool, string, error) {
+			var stillPresent []TrackedObject
+			for _, object := range pending {
+				_, err := k8s.getTrackedObjectMeta(object)
+				if k8serrors.IsNotFound(err) {
+					if k8s.Tracker != nil {
+						k8s.Tracker.forget(object)
+					}
+					deleted = append(deleted, object)
+					k8s.Logger.PrintlnDebugMessage("deleted", object)
+					continue
+				}
+				stillPresent = append(stillPresent, object)
+			}
+			pending = stillPresent
+			return len(pending) == 0, fmt.Sprintf("%d objects of kind %s still present", len(pending), objects[start].Kind), nil
+		})
+		if err != nil {
+			for _, object := range pending {
+				failures[object] = k8s.pendingReason(object, timeout)
+			}
+		}
+		start = end
+	}
+
+	if len(failures) != 0 {
+		return deleted, &CleanupError{Failures: failures}
+	}
+	return deleted, nil
+}
+
+// pendingReason returns why the object is still present after timeout, mentioning its finalizers if any
+func (k8s K8S) pendingReason(object TrackedObject, timeout time.Duration) error {
+	meta, err := k8s.getTrackedObjectMeta(object)
+	if err != nil {
+		return fmt.Errorf("still present after %v, error getting it: %v", timeout, err)
+	}
+	if finalizers := meta.GetFinalizers(); len(finalizers) != 0 {
+		return fmt.Errorf("still present after %v with finalizers %q", timeout, finalizers)
+	}
+	return fmt.Errorf("still present after %v", timeout)
+}
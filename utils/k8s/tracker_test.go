@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortForDeletion(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Track(KindStoragePoolClaim, "", "spc")
+	tracker.Track(KindStorageClass, "", "sc")
+	tracker.Track(KindPersistentVolumeClaim, "default", "pvc-1")
+	tracker.Track(KindDeployment, "default", "app")
+	tracker.Track(KindPersistentVolumeClaim, "default", "pvc-2")
+
+	expected := []TrackedObject{
+		{Kind: KindDeployment, Namespace: "default", Name: "app"},
+		{Kind: KindPersistentVolumeClaim, Namespace: "default", Name: "pvc-2"},
+		{Kind: KindPersistentVolumeClaim, Namespace: "default", Name: "pvc-1"},
+		{Kind: KindStorageClass, Name: "sc"},
+		{Kind: KindStoragePoolClaim, Name: "spc"},
+	}
+	if sorted := sortForDeletion(tracker.Objects()); !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("deletion order is %v, expected %v", sorted, expected)
+	}
+}
+
+func TestTrackerForget(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Track(KindStorageClass, "", "first")
+	tracker.Track(KindStorageClass, "", "second")
+	tracker.forget(TrackedObject{Kind: KindStorageClass, Name: "first"})
+
+	expected := []TrackedObject{{Kind: KindStorageClass, Name: "second"}}
+	if objects := tracker.Objects(); !reflect.DeepEqual(objects, expected) {
+		t.Errorf("tracked objects are %v, expected %v", objects, expected)
+	}
+}
+
+func TestDeleteTrackedObjectsWithoutTracker(t *testing.T) {
+	if err := (K8S{}).DeleteTrackedObjects(time.Second); err != nil {
+		t.Errorf("expected no error when tracking is not enabled, got: %+v", err)
+	}
+}
+
+func TestCleanupErrorMessage(t *testing.T) {
+	err := &CleanupError{Failures: map[TrackedObject]error{
+		{Kind: KindStorageClass, Name: "sc"}:                                 errors.New("forbidden"),
+		{Kind: KindPersistentVolumeClaim, Namespace: "default", Name: "pvc"}: errors.New("still present"),
+	}}
+	expected := "failed to remove 2 tracked objects: PersistentVolumeClaim/default/pvc: still present; StorageClass/sc: forbidden"
+	if err.Error() != expected {
+		t.Errorf("error message is %q, expected %q", err.Error(), expected)
+	}
+}
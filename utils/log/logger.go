@@ -1,200 +1,419 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
 package log
 
 import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log record, records below the level of a Logger are dropped
+type Level int
 
-	"github.com/golang/glog"
+// Levels of the log records in increasing order of severity
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
-// DebugEnabled specifies if this package print debug information
-var DebugEnabled = false
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
 
-// Logger is a struct which will help to call CITF specific logging functions
-type Logger struct{}
+func (level Level) String() string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(level))
+}
 
-// WritefDebugMessage formats according to a format specifier and writes to w only when DebugEnabled is true.
-// A newline is always appended. It returns the number of bytes written and any write error encountered.
-func (logger Logger) WritefDebugMessage(w io.Writer, format string, a ...interface{}) (n int, err error) {
-	if DebugEnabled {
-		return fmt.Fprintf(w, format+"\n", a...)
+// ParseLevel returns the Level for its name i.e. one of "debug", "info", "warn" and "error"
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(name), levelName) {
+			return level, nil
+		}
 	}
-	return
+	return LevelInfo, fmt.Errorf("unknown log level %q, should be one of \"debug\", \"info\", \"warn\" or \"error\"", name)
 }
 
-// PrintfDebugMessage formats according to a format specifier and writes to standard output only when DebugEnabled is true.
-// //  A newline is always appended. It returns the number of bytes written and any write error encountered.
-func (logger Logger) PrintfDebugMessage(format string, a ...interface{}) (n int, err error) {
-	return logger.WritefDebugMessage(os.Stdout, format, a...)
+// Format is the way log records are written
+type Format string
+
+const (
+	// FormatText writes a record per line as time, level, message and then key=value fields
+	FormatText Format = "text"
+	// FormatJSON writes a record per line as a JSON object with keys time, level, msg and the fields
+	FormatJSON Format = "json"
+)
+
+// Options are the options of a Logger
+type Options struct {
+	// Level is the minimum level of the records which are written
+	Level Level
+	// Format is the format of the records, blank means FormatText
+	Format Format
+	// Sinks are the writers every record is written to, empty means os.Stderr
+	Sinks []io.Writer
 }
 
-// WritelnDebugMessage formats using the default formats for its operands and writes to w only when DebugEnabled.
-// Spaces are always added between operands and a newline is appended.
-// It returns the number of bytes written and any write error encountered.
-func (logger Logger) WritelnDebugMessage(w io.Writer, a ...interface{}) (n int, err error) {
-	if DebugEnabled {
-		return fmt.Fprintln(w, a...)
+// output is shared by a Logger and all the Loggers derived from it by With
+type output struct {
+	mutex   sync.Mutex
+	options Options
+}
+
+// defaultOutput is used by the zero value of Logger, e.g. package level loggers of CITF
+var defaultOutput = newOutput(Options{Level: LevelInfo})
+
+func newOutput(options Options) *output {
+	out := &output{}
+	out.setOptions(options)
+	return out
+}
+
+func (out *output) setOptions(options Options) {
+	if options.Format == "" {
+		options.Format = FormatText
 	}
-	return
+	if len(options.Sinks) == 0 {
+		options.Sinks = []io.Writer{os.Stderr}
+	}
+	out.mutex.Lock()
+	defer out.mutex.Unlock()
+	out.options = options
+}
+
+func (out *output) level() Level {
+	out.mutex.Lock()
+	defer out.mutex.Unlock()
+	return out.options.Level
 }
 
-// PrintlnDebugMessage formats using the default formats for its operands and writes to standard output only when DebugEnabled.
-// Spaces are always added between operands and a newline is appended.
+// write encodes the record and writes it to the sinks, or to w if it is not nil
+func (out *output) write(w io.Writer, record record) (n int, err error) {
+	out.mutex.Lock()
+	defer out.mutex.Unlock()
+
+	line := record.encode(out.options.Format)
+	if w != nil {
+		return w.Write(line)
+	}
+	for _, sink := range out.options.Sinks {
+		written, writeErr := sink.Write(line)
+		if writeErr != nil && err == nil {
+			n, err = written, writeErr
+		} else if err == nil {
+			n = written
+		}
+	}
+	return n, err
+}
+
+// SetDefault sets the options of the zero value of Logger i.e. of the package level loggers of CITF
+func SetDefault(options Options) {
+	defaultOutput.setOptions(options)
+}
+
+// Logger is a leveled logger which writes records with key/value fields in text or JSON.
+// Zero value of Logger writes as per the options given to SetDefault.
+type Logger struct {
+	out    *output
+	fields []interface{}
+}
+
+// New returns a Logger with the supplied options
+func New(options Options) Logger {
+	return Logger{out: newOutput(options)}
+}
+
+// NewLogger returns a Logger which writes debug records only if debugEnabled is true
+func NewLogger(debugEnabled bool) Logger {
+	level := LevelInfo
+	if debugEnabled {
+		level = LevelDebug
+	}
+	return New(Options{Level: level})
+}
+
+func (logger Logger) output() *output {
+	if logger.out != nil {
+		return logger.out
+	}
+	return defaultOutput
+}
+
+// SetOptions changes the options of logger, every Logger derived from it by With is changed too.
+// Changing options of the zero value of Logger is same as SetDefault.
+func (logger Logger) SetOptions(options Options) {
+	logger.output().setOptions(options)
+}
+
+// Options returns the options of logger
+func (logger Logger) Options() Options {
+	out := logger.output()
+	out.mutex.Lock()
+	defer out.mutex.Unlock()
+	return out.options
+}
+
+// With returns a Logger which adds the supplied key/value pairs to every record,
+// it writes to the same sinks as logger.
+func (logger Logger) With(keyValues ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(logger.fields)+len(keyValues))
+	fields = append(append(fields, logger.fields...), keyValues...)
+	return Logger{out: logger.out, fields: fields}
+}
+
+// Enabled tells whether records of the level supplied are written by logger
+func (logger Logger) Enabled(level Level) bool {
+	return level >= logger.output().level()
+}
+
+// log writes the record, or to w if it is not nil, if level is enabled
+func (logger Logger) log(w io.Writer, level Level, message string, keyValues ...interface{}) (n int, err error) {
+	if !logger.Enabled(level) {
+		return
+	}
+	fields := make([]interface{}, 0, len(logger.fields)+len(keyValues))
+	fields = append(append(fields, logger.fields...), keyValues...)
+	return logger.output().write(w, record{
+		time:    time.Now(),
+		level:   level,
+		message: strings.TrimRight(message, "\n"),
+		fields:  fields,
+	})
+}
+
+// Debug writes the message with key/value pairs as a debug record
+func (logger Logger) Debug(message string, keyValues ...interface{}) {
+	logger.log(nil, LevelDebug, message, keyValues...)
+}
+
+// Info writes the message with key/value pairs as an info record
+func (logger Logger) Info(message string, keyValues ...interface{}) {
+	logger.log(nil, LevelInfo, message, keyValues...)
+}
+
+// Warn writes the message with key/value pairs as a warn record
+func (logger Logger) Warn(message string, keyValues ...interface{}) {
+	logger.log(nil, LevelWarn, message, keyValues...)
+}
+
+// Error writes the message with key/value pairs as an error record
+func (logger Logger) Error(message string, keyValues ...interface{}) {
+	logger.log(nil, LevelError, message, keyValues...)
+}
+
+// sprintln is fmt.Sprintln without the trailing newline
+func sprintln(a ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
+
+// WritefDebugMessage formats according to a format specifier and writes it as a debug record to w.
 // It returns the number of bytes written and any write error encountered.
+func (logger Logger) WritefDebugMessage(w io.Writer, format string, a ...interface{}) (n int, err error) {
+	return logger.log(w, LevelDebug, fmt.Sprintf(format, a...))
+}
+
+// PrintfDebugMessage formats according to a format specifier and writes it as a debug record.
+// It returns the number of bytes written and any write error encountered.
+func (logger Logger) PrintfDebugMessage(format string, a ...interface{}) (n int, err error) {
+	return logger.log(nil, LevelDebug, fmt.Sprintf(format, a...))
+}
+
+// WritelnDebugMessage formats using the default formats for its operands and writes it as a debug record to w.
+// Spaces are always added between operands. It returns the number of bytes written and any write error encountered.
+func (logger Logger) WritelnDebugMessage(w io.Writer, a ...interface{}) (n int, err error) {
+	return logger.log(w, LevelDebug, sprintln(a...))
+}
+
+// PrintlnDebugMessage formats using the default formats for its operands and writes it as a debug record.
+// Spaces are always added between operands. It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintlnDebugMessage(a ...interface{}) (n int, err error) {
-	return logger.WritelnDebugMessage(os.Stdout, a...)
+	return logger.log(nil, LevelDebug, sprintln(a...))
 }
 
-// LogError logs error using `glog.Error` only when err is not nil.
+// LogError writes the message as an error record, with err in field "error", only when err is not nil.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) LogError(err error, message string) {
 	if err != nil {
-		glog.Error(message+":", err)
+		logger.Error(message, "error", err)
 	}
 }
 
-// LogNonError logs info using `glog.Info` only when err is nil.
+// LogNonError writes the message as an info record only when err is nil.
 func (logger Logger) LogNonError(err error, message string) {
 	if err == nil {
-		glog.Info(message)
+		logger.Info(message)
 	}
 }
 
-// LogErrorf formats according to a format specifier and logs error using `glog.Error` only when err is not nil.
+// LogErrorf formats according to a format specifier and writes it as an error record,
+// with err in field "error", only when err is not nil.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) LogErrorf(err error, message string, a ...interface{}) {
 	if err != nil {
-		glog.Error(fmt.Sprintf(message, a...)+":", err)
+		logger.Error(fmt.Sprintf(message, a...), "error", err)
 	}
 }
 
-// LogNonErrorf logs info using `glog.Infof` only when err is nil.
-// formatting is taken care by `glog.Infof`
+// LogNonErrorf formats according to a format specifier and writes it as an info record only when err is nil.
 func (logger Logger) LogNonErrorf(err error, message string, a ...interface{}) {
 	if err == nil {
-		glog.Infof(message, a...)
+		logger.Info(fmt.Sprintf(message, a...))
 	}
 }
 
-// LogFatal logs error using `glog.Error` only when err is not nil.
+// LogFatal writes the message as an error record and exits only when err is not nil.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) LogFatal(err error, message string) {
 	if err != nil {
-		glog.Fatal(message+":", err)
+		logger.Error(message, "error", err)
+		os.Exit(255)
 	}
 }
 
-// LogFatalf formats according to a format specifier and logs error using `glog.Error` only when err is not nil.
+// LogFatalf formats according to a format specifier and writes it as an error record and exits only when err is not nil.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) LogFatalf(err error, message string, a ...interface{}) {
 	if err != nil {
-		glog.Fatal(fmt.Sprintf(message, a...)+":", err)
+		logger.Error(fmt.Sprintf(message, a...), "error", err)
+		os.Exit(255)
 	}
 }
 
-// PrintError wtires error message to os.StdErr only when err is not nil.
-// Spaces are always added between operands and a newline is appended.
+// PrintError writes the message as an error record, with err in field "error", only when err is not nil.
 // It returns the number of bytes written and any write error encountered.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) PrintError(err error, message string) (n int, errr error) {
 	if err != nil {
-		return fmt.Fprintln(os.Stderr, message+":", err)
+		return logger.log(nil, LevelError, message, "error", err)
 	}
 	return
 }
 
-// PrintNonError wtites info message to os.Stdout only when err is nil.
-// Spaces are always added between operands and a newline is appended.
+// PrintNonError writes the message as an info record only when err is nil.
 // It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintNonError(err error, message string) (n int, errr error) {
 	if err == nil {
-		return fmt.Println(message)
+		return logger.log(nil, LevelInfo, message)
 	}
 	return
 }
 
-// PrintErrorf formats according to a format specifier and wtires error message to os.StdErr only when err is not nil.
-// A newline is always appended. It returns the number of bytes written and any write error encountered.
+// PrintErrorf formats according to a format specifier and writes it as an error record,
+// with err in field "error", only when err is not nil.
+// It returns the number of bytes written and any write error encountered.
 // Please follow convensions for error message e.g. start with lowercase, don't end with period etc.
 func (logger Logger) PrintErrorf(err error, message string, a ...interface{}) (n int, errr error) {
 	if err != nil {
-		a = append(a, err)
-		return fmt.Fprintf(os.Stderr, message+":%+v\n", a...)
+		return logger.log(nil, LevelError, fmt.Sprintf(message, a...), "error", err)
 	}
 	return
 }
 
-// PrintNonErrorf formats according to a format specifier and wtites info message to os.Stdout only when err is nil.
-// A newline is always appended. It returns the number of bytes written and any write error encountered.
+// PrintNonErrorf formats according to a format specifier and writes it as an info record only when err is nil.
+// It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintNonErrorf(err error, message string, a ...interface{}) (n int, errr error) {
 	if err == nil {
-		return fmt.Printf(message+"\n", a...)
+		return logger.log(nil, LevelInfo, fmt.Sprintf(message, a...))
 	}
 	return
 }
 
-// WritefDebugMessageIfError formats according to a format specifier and writes to w only when DebugEnabled is true and err is not nil.
-//  A newline is always appended. It returns the number of bytes written and any write error encountered.
+// WritefDebugMessageIfError formats according to a format specifier and writes it as a debug record to w,
+// with err in field "error", only when err is not nil.
+// It returns the number of bytes written and any write error encountered.
 func (logger Logger) WritefDebugMessageIfError(err error, w io.Writer, format string, a ...interface{}) (n int, errr error) {
 	if err != nil {
-		a = append(a, err)
-		return logger.WritefDebugMessage(w, format+": %+v", a...)
+		return logger.log(w, LevelDebug, fmt.Sprintf(format, a...), "error", err)
 	}
 	return
 }
 
-// PrintfDebugMessageIfError formats according to a format specifier and writes to standard output only when DebugEnabled is true and err is not nil.
+// PrintfDebugMessageIfError formats according to a format specifier and writes it as a debug record,
+// with err in field "error", only when err is not nil.
 // It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintfDebugMessageIfError(err error, format string, a ...interface{}) (n int, errr error) {
-	return logger.WritefDebugMessageIfError(err, os.Stderr, format, a...)
+	if err != nil {
+		return logger.log(nil, LevelDebug, fmt.Sprintf(format, a...), "error", err)
+	}
+	return
 }
 
-// WritelnDebugMessageIfError formats using the default formats for its operands and writes to w only when DebugEnabled and err is not nil.
-// Spaces are always added between operands and a newline is appended.
+// WritelnDebugMessageIfError formats using the default formats for its operands and writes it as a debug record to w,
+// with err in field "error", only when err is not nil.
 // It returns the number of bytes written and any write error encountered.
 func (logger Logger) WritelnDebugMessageIfError(err error, w io.Writer, a ...interface{}) (n int, errr error) {
 	if err != nil {
-		return logger.WritefDebugMessage(w, fmt.Sprintln(a...)+": %+v", err)
+		return logger.log(w, LevelDebug, sprintln(a...), "error", err)
 	}
 	return
 }
 
-// PrintlnDebugMessageIfError formats using the default formats for its operands and writes to standard output only when DebugEnabled and err is not nil.
-// Spaces are always added between operands and a newline is appended.
+// PrintlnDebugMessageIfError formats using the default formats for its operands and writes it as a debug record,
+// with err in field "error", only when err is not nil.
 // It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintlnDebugMessageIfError(err error, a ...interface{}) (n int, errr error) {
-	return logger.WritelnDebugMessageIfError(err, os.Stderr, a...)
+	if err != nil {
+		return logger.log(nil, LevelDebug, sprintln(a...), "error", err)
+	}
+	return
 }
 
-// WritefDebugMessageIfNotError formats according to a format specifier and writes to w only when DebugEnabled is true and err is nil.
-//  A newline is always appended. It returns the number of bytes written and any write error encountered.
+// WritefDebugMessageIfNotError formats according to a format specifier and writes it as a debug record to w
+// only when err is nil. It returns the number of bytes written and any write error encountered.
 func (logger Logger) WritefDebugMessageIfNotError(err error, w io.Writer, format string, a ...interface{}) (n int, errr error) {
 	if err == nil {
-		return logger.WritefDebugMessage(w, format+": %+v", a...)
+		return logger.log(w, LevelDebug, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
-// PrintfDebugMessageIfNotError formats according to a format specifier and writes to standard output only when DebugEnabled is true and err is nil.
-// It returns the number of bytes written and any write error encountered.
+// PrintfDebugMessageIfNotError formats according to a format specifier and writes it as a debug record
+// only when err is nil. It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintfDebugMessageIfNotError(err error, format string, a ...interface{}) (n int, errr error) {
-	return logger.WritefDebugMessageIfNotError(err, os.Stderr, format, a...)
+	if err == nil {
+		return logger.log(nil, LevelDebug, fmt.Sprintf(format, a...))
+	}
+	return
 }
 
-// WritelnDebugMessageIfNotError formats using the default formats for its operands and writes to w only when DebugEnabled and err is nil.
-// Spaces are always added between operands and a newline is appended.
-// It returns the number of bytes written and any write error encountered.
+// WritelnDebugMessageIfNotError formats using the default formats for its operands and writes it as a debug record to w
+// only when err is nil. It returns the number of bytes written and any write error encountered.
 func (logger Logger) WritelnDebugMessageIfNotError(err error, w io.Writer, a ...interface{}) (n int, errr error) {
 	if err == nil {
-		return logger.WritelnDebugMessage(w, a...)
+		return logger.log(w, LevelDebug, sprintln(a...))
 	}
 	return
 }
 
-// PrintlnDebugMessageIfNotError formats using the default formats for its operands and writes to standard output only when DebugEnabled and err is nil.
-// Spaces are always added between operands and a newline is appended.
-// It returns the number of bytes written and any write error encountered.
+// PrintlnDebugMessageIfNotError formats using the default formats for its operands and writes it as a debug record
+// only when err is nil. It returns the number of bytes written and any write error encountered.
 func (logger Logger) PrintlnDebugMessageIfNotError(err error, a ...interface{}) (n int, errr error) {
-	return logger.WritelnDebugMessageIfNotError(err, os.Stderr, a...)
+	if err == nil {
+		return logger.log(nil, LevelDebug, sprintln(a...))
+	}
+	return
 }
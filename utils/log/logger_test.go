@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{name: "debug", want: LevelDebug},
+		{name: "INFO", want: LevelInfo},
+		{name: " warn ", want: LevelWarn},
+		{name: "error", want: LevelError},
+		{name: "verbose", want: LevelInfo, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Options{Level: LevelWarn, Sinks: []io.Writer{buf}})
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Warn("warn record")
+	logger.Error("error record")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logger with level warn wrote %d records, want 2: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "WARN  warn record") || !strings.Contains(lines[1], "ERROR error record") {
+		t.Errorf("logger with level warn wrote unexpected records: %q", buf.String())
+	}
+}
+
+func TestLoggerText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Options{Level: LevelDebug, Sinks: []io.Writer{buf}}).With("component", "k8s")
+
+	logger.Info("created pod\n", "namespace", "citf-test", "reason", "it is ready", "err", errors.New("none"), "odd")
+
+	got := buf.String()
+	for _, want := range []string{
+		" INFO  created pod ",
+		"component=k8s",
+		"namespace=citf-test",
+		`reason="it is ready"`,
+		"err=none",
+		missingKey + "=odd",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("text record %q does not contain %q", got, want)
+		}
+	}
+	if strings.Count(got, "\n") != 1 {
+		t.Errorf("text record %q is not a single line", got)
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Options{Level: LevelDebug, Format: FormatJSON, Sinks: []io.Writer{buf}})
+
+	logger.With("run", "20180101").Error("deletion failed", "pending", 2, "namespaces", []string{"a", "b"})
+
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("JSON record %q can not be parsed. Error: %+v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"level":      "error",
+		"msg":        "deletion failed",
+		"run":        "20180101",
+		"pending":    float64(2),
+		"namespaces": []interface{}{"a", "b"},
+	}
+	for key, value := range want {
+		gotJSON, _ := json.Marshal(record[key])
+		wantJSON, _ := json.Marshal(value)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("JSON record has %q = %s, want %s", key, gotJSON, wantJSON)
+		}
+	}
+	if _, ok := record["time"]; !ok {
+		t.Errorf("JSON record %q has no time", buf.String())
+	}
+}
+
+func TestLoggerSinksAndSetOptions(t *testing.T) {
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New(Options{Level: LevelInfo, Sinks: []io.Writer{first, second}})
+	derived := logger.With("key", "value")
+
+	derived.Debug("not written")
+	logger.SetOptions(Options{Level: LevelDebug, Sinks: []io.Writer{first, second}})
+	derived.Debug("written")
+
+	for _, buf := range []*bytes.Buffer{first, second} {
+		if strings.Contains(buf.String(), "not written") || !strings.Contains(buf.String(), "written key=value") {
+			t.Errorf("sink got %q, want only the record written after changing level to debug", buf.String())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormat is the format of the time of the records
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// missingKey is the key of the value which has no key i.e. last of odd number of key/values
+const missingKey = "!MISSINGKEY"
+
+// record is a single log record
+type record struct {
+	time    time.Time
+	level   Level
+	message string
+	// fields are key/value pairs
+	fields []interface{}
+}
+
+// pairs returns the key/value pairs of the fields, keys are converted to string
+func (record record) pairs() ([]string, []interface{}) {
+	var keys []string
+	var values []interface{}
+	for i := 0; i < len(record.fields); i += 2 {
+		if i+1 == len(record.fields) {
+			keys = append(keys, missingKey)
+			values = append(values, record.fields[i])
+			break
+		}
+		keys = append(keys, fmt.Sprint(record.fields[i]))
+		values = append(values, record.fields[i+1])
+	}
+	return keys, values
+}
+
+// plainValue returns the value as it should be encoded i.e. errors and Stringers as their string
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+// encode returns the record encoded in the format supplied, terminated by a newline
+func (record record) encode(format Format) []byte {
+	keys, values := record.pairs()
+	if format == FormatJSON {
+		return record.encodeJSON(keys, values)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %-5s %s", record.time.Format(timeFormat), strings.ToUpper(record.level.String()), record.message)
+	for i, key := range keys {
+		buf.WriteString(" " + key + "=" + textValue(plainValue(values[i])))
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// textValue returns the value as it is written in text format, quoted if it has spaces or quotes
+func textValue(value interface{}) string {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []string:
+		str = strings.Join(v, ",")
+	default:
+		str = fmt.Sprintf("%+v", v)
+	}
+	if str == "" || strings.ContainsAny(str, " \t\n\"=") {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+// encodeJSON returns the record as a JSON object in a line. Values which can not be marshalled are written as strings.
+func (record record) encodeJSON(keys []string, values []interface{}) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("{")
+	writeJSONField(buf, "time", record.time.Format(timeFormat))
+	buf.WriteString(",")
+	writeJSONField(buf, "level", record.level.String())
+	buf.WriteString(",")
+	writeJSONField(buf, "msg", record.message)
+	for i, key := range keys {
+		buf.WriteString(",")
+		writeJSONField(buf, key, plainValue(values[i]))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}) {
+	keyBytes, _ := json.Marshal(key)
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		valueBytes, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+	buf.Write(keyBytes)
+	buf.WriteString(":")
+	buf.Write(valueBytes)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// TestingT is the part of *testing.T (and of ginkgo's GinkgoT()) which is needed to write to the output of a test
+type TestingT interface {
+	Log(args ...interface{})
+}
+
+// testWriter writes every complete line written to it as a log of the test
+type testWriter struct {
+	mutex sync.Mutex
+	t     TestingT
+	// partial is the last line written which is not yet terminated by newline
+	partial []byte
+}
+
+// TestWriter returns a writer which writes every line written to it with `t.Log`, so the output is
+// kept with the test which produced it and shown by `go test` only if the test fails or with -v.
+// It must not be written to after the test has completed, as `testing` does not allow it.
+func TestWriter(t TestingT) io.Writer {
+	return &testWriter{t: t}
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.t.Log(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
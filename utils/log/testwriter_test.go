@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordingT records what is logged to it
+type recordingT struct {
+	logs []string
+}
+
+func (t *recordingT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestTestWriter(t *testing.T) {
+	recorder := &recordingT{}
+	w := TestWriter(recorder)
+
+	fmt.Fprint(w, "first line\nsecond ")
+	fmt.Fprint(w, "line\n")
+	fmt.Fprint(w, "incomplete")
+
+	want := []string{"first line", "second line"}
+	if !reflect.DeepEqual(recorder.logs, want) {
+		t.Errorf("TestWriter logged %q, want %q", recorder.logs, want)
+	}
+}
+
+func TestLoggerToTestWriter(t *testing.T) {
+	recorder := &recordingT{}
+	logger := New(Options{Level: LevelInfo, Sinks: []io.Writer{TestWriter(recorder)}})
+
+	logger.Info("waiting for pod", "pod", "nginx")
+
+	if len(recorder.logs) != 1 || !strings.Contains(recorder.logs[0], "waiting for pod pod=nginx") {
+		t.Errorf("logger writing to TestWriter logged %q, want one record of the pod", recorder.logs)
+	}
+}
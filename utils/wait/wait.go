@@ -0,0 +1,178 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wait polls a condition until it is met, an error occurs or the context ends.
+// Every wait of CITF is built on it, so they all stop on cancellation of their context,
+// sleep between the checks and tell what they saw last when they give up.
+package wait
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the interval between two checks when Options.Interval is not set
+const DefaultInterval = time.Second
+
+// Condition tells whether what is waited for has happened. It returns the state it observed, which is
+// reported if the wait ends before the condition is met, and a non-nil error to stop waiting with that error.
+// Errors which are worth retrying (e.g. of an api call) should be returned as the state with done as false.
+type Condition func(ctx context.Context) (done bool, state string, err error)
+
+// Options tell how often the condition is checked and for how long
+type Options struct {
+	// Interval is the time between first two checks, DefaultInterval if zero
+	Interval time.Duration
+	// Backoff is the factor by which the interval grows after every check, values up to 1 mean constant interval
+	Backoff float64
+	// MaxInterval is the maximum interval when it grows by Backoff, zero means no limit
+	MaxInterval time.Duration
+	// Jitter is the fraction of the interval by which every interval is randomly lengthened e.g. 0.1 for up to 10%,
+	// so that many waits started together do not check at the same time
+	Jitter float64
+	// Timeout is the maximum time to wait, zero means until the context ends
+	Timeout time.Duration
+}
+
+// TimeoutError is returned by Poll when the context ends, or Timeout passes, before the condition is met
+type TimeoutError struct {
+	// Waited is the time spent in waiting
+	Waited time.Duration
+	// Checks is the number of times the condition was checked
+	Checks int
+	// LastState is the state returned by the last check of the condition
+	LastState string
+	// Cause is the error of the context i.e. context.DeadlineExceeded or context.Canceled
+	Cause error
+}
+
+func (err *TimeoutError) Error() string {
+	reason := "timed out"
+	if err.Cause == context.Canceled {
+		reason = "cancelled"
+	}
+	message := fmt.Sprintf("%s after %v and %d checks", reason, err.Waited.Round(time.Millisecond), err.Checks)
+	if err.LastState != "" {
+		message += ", last state: " + err.LastState
+	}
+	return message
+}
+
+// IsTimeout tells whether the error is returned because the wait ended before the condition was met
+func IsTimeout(err error) bool {
+	_, ok := err.(*TimeoutError)
+	return ok
+}
+
+var (
+	randMutex sync.Mutex
+	random    = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// jittered returns the interval lengthened by a random fraction of it, up to `jitter`
+func jittered(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	randMutex.Lock()
+	defer randMutex.Unlock()
+	return interval + time.Duration(random.Float64()*jitter*float64(interval))
+}
+
+// next returns the interval after the interval supplied as per the backoff of opts
+func (opts Options) next(interval time.Duration) time.Duration {
+	if opts.Backoff <= 1 {
+		return interval
+	}
+	interval = time.Duration(float64(interval) * opts.Backoff)
+	if opts.MaxInterval > 0 && interval > opts.MaxInterval {
+		interval = opts.MaxInterval
+	}
+	return interval
+}
+
+// Poll checks the condition right away and then after every interval, as per opts, until it is met (nil is returned),
+// it returns an error (that error is returned) or ctx ends or `opts.Timeout` passes (*TimeoutError is returned).
+// It does not start any goroutine and returns as soon as ctx ends, even in between the checks.
+func Poll(ctx context.Context, opts Options, condition Condition) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	start := time.Now()
+	timeoutErr := &TimeoutError{}
+	for {
+		done, state, err := condition(ctx)
+		timeoutErr.Checks++
+		timeoutErr.LastState = state
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(jittered(interval, opts.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			timeoutErr.Waited = time.Since(start)
+			timeoutErr.Cause = ctx.Err()
+			return timeoutErr
+		case <-timer.C:
+		}
+		interval = opts.next(interval)
+	}
+}
+
+// PollTimeout is same as Poll with a background context, i.e. it waits for at most `opts.Timeout`
+// (forever if it is zero)
+func PollTimeout(opts Options, condition Condition) error {
+	return Poll(context.Background(), opts, condition)
+}
+
+// QuitContext returns a context which is cancelled when `true` is received from quit, values `false` are ignored.
+// It is meant for the APIs which are stopped with a quit channel. The returned CancelFunc must be called
+// when the wait is over, so that the goroutine reading quit ends even if nothing is ever sent to it.
+func QuitContext(parent context.Context, quit <-chan bool) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	if quit == nil {
+		return ctx, cancel
+	}
+	go func() {
+		for {
+			select {
+			case quitting, ok := <-quit:
+				if !ok {
+					return
+				}
+				if quitting {
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ctx, cancel
+}
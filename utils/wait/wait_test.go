@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The OpenEBS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	checks := 0
+	err := PollTimeout(Options{Interval: time.Millisecond}, func(context.Context) (bool, string, error) {
+		checks++
+		return checks == 3, fmt.Sprintf("check %d", checks), nil
+	})
+	if err != nil || checks != 3 {
+		t.Errorf("expected condition to be met at third check, got %d checks and error: %+v", checks, err)
+	}
+
+	terminal := errors.New("pod has terminated")
+	err = PollTimeout(Options{Interval: time.Millisecond}, func(context.Context) (bool, string, error) {
+		return false, "", terminal
+	})
+	if err != terminal {
+		t.Errorf("expected error of the condition, got: %+v", err)
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	checks := 0
+	err := PollTimeout(Options{Interval: 5 * time.Millisecond, Timeout: 30 * time.Millisecond}, func(context.Context) (bool, string, error) {
+		checks++
+		return false, fmt.Sprintf("pod is Pending at check %d", checks), nil
+	})
+
+	timeoutErr, ok := err.(*TimeoutError)
+	if !ok || !IsTimeout(err) {
+		t.Fatalf("expected *TimeoutError, got: %+v", err)
+	}
+	if timeoutErr.Cause != context.DeadlineExceeded || timeoutErr.Checks != checks || checks < 2 {
+		t.Errorf("unexpected cause %v or checks %d (condition checked %d times)", timeoutErr.Cause, timeoutErr.Checks, checks)
+	}
+	if timeoutErr.LastState != fmt.Sprintf("pod is Pending at check %d", checks) {
+		t.Errorf("expected the state of the last check, got: %q", timeoutErr.LastState)
+	}
+	if !strings.HasPrefix(err.Error(), "timed out after") || !strings.HasSuffix(err.Error(), "last state: "+timeoutErr.LastState) {
+		t.Errorf("unexpected error message: %q", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Poll(ctx, Options{Interval: time.Hour}, func(context.Context) (bool, string, error) {
+		return false, "", nil
+	})
+	if !IsTimeout(err) || !strings.HasPrefix(err.Error(), "cancelled after") || err.(*TimeoutError).Checks != 1 {
+		t.Errorf("expected cancelled context to end the wait after one check, got: %+v", err)
+	}
+}
+
+func TestNext(t *testing.T) {
+	opts := Options{Backoff: 2, MaxInterval: 300 * time.Millisecond}
+	interval := 100 * time.Millisecond
+	for _, expected := range []time.Duration{200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond} {
+		if interval = opts.next(interval); interval != expected {
+			t.Errorf("next() = %v, expected %v", interval, expected)
+		}
+	}
+
+	if interval := (Options{}).next(time.Second); interval != time.Second {
+		t.Errorf("expected constant interval without backoff, got: %v", interval)
+	}
+}
+
+func TestJittered(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if interval := jittered(time.Second, 0.1); interval < time.Second || interval > 1100*time.Millisecond {
+			t.Fatalf("jittered interval %v is out of [1s, 1.1s]", interval)
+		}
+	}
+	if interval := jittered(time.Second, 0); interval != time.Second {
+		t.Errorf("expected no jitter, got: %v", interval)
+	}
+}
+
+func TestQuitContext(t *testing.T) {
+	quit := make(chan bool)
+	ctx, cancel := QuitContext(context.Background(), quit)
+	defer cancel()
+
+	quit <- false
+	select {
+	case <-ctx.Done():
+		t.Fatalf("context is cancelled by `false`")
+	case <-time.After(10 * time.Millisecond):
+	}
+	quit <- true
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("context is not cancelled by `true`")
+	}
+
+	// goroutine reading quit ends when the context is cancelled, even if nothing is ever sent to quit
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		_, cancel := QuitContext(context.Background(), make(chan bool))
+		cancel()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if leaked := runtime.NumGoroutine() - goroutines; leaked > 0 {
+		t.Errorf("%d goroutines are leaked", leaked)
+	}
+}